@@ -0,0 +1,100 @@
+package gogo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtariGo(t *testing.T) {
+	tests := []struct {
+		opts         []Option
+		actions      []string
+		expectOver   bool
+		expectWinner string
+	}{
+		{opts: []Option{WithAtariGo()}, actions: []string{"B1", "A1"}},
+		// the first capture wins
+		{
+			opts:         []Option{WithAtariGo()},
+			actions:      []string{"B1", "A1", "A2"},
+			expectOver:   true,
+			expectWinner: blackPlayer,
+		},
+		// capturing your own stone hands the win to the other player
+		{
+			opts:         []Option{WithAtariGo()},
+			actions:      []string{"D4", "A2", "D3", "B1", "A1"},
+			expectOver:   true,
+			expectWinner: whitePlayer,
+		},
+		// in a three player game, the capturer wins whoever they took
+		// the stone from
+		{
+			opts:         []Option{WithAtariGo(), WithPlayers(3)},
+			actions:      []string{"B1", "A1", "A2"},
+			expectOver:   true,
+			expectWinner: redPlayer,
+		},
+		// a normal game carries on
+		{actions: []string{"B1", "A1", "A2"}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewBoard(4, tt.opts...)
+			require.NoError(t, err)
+
+			last := tt.actions[len(tt.actions)-1]
+			require.NoError(t, play(&board, tt.actions[:len(tt.actions)-1]))
+			res, err := board.Play(last)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectWinner, res.Winner())
+			assert.Equal(t, tt.expectWinner, board.Winner())
+			assert.Equal(t, tt.expectOver, board.GameOver())
+
+			if tt.expectOver {
+				_, err := board.Play("C3")
+				assert.Error(t, err)
+				_, err = board.Pass()
+				assert.Error(t, err)
+			}
+
+			// the variant is kept when the game's saved and loaded
+			data, err := json.Marshal(&board)
+			require.NoError(t, err)
+			var fromJSON Board
+			require.NoError(t, json.Unmarshal(data, &fromJSON))
+			assert.Equal(t, board.Rules(), fromJSON.Rules())
+
+			store := NewMemoryStore()
+			require.NoError(t, store.Save(&board))
+			loaded, err := store.Load(board.Code())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectWinner, loaded.Winner())
+			assert.Equal(t, tt.expectOver, loaded.GameOver())
+		})
+	}
+}
+
+func TestUndoWinningCaptureInAtariGo(t *testing.T) {
+	board, err := NewBoard(4, WithAtariGo())
+	require.NoError(t, err)
+	require.NoError(t, play(&board, []string{"B1", "A1", "A2"}))
+	require.True(t, board.GameOver())
+
+	require.NoError(t, board.Undo())
+	assert.False(t, board.GameOver())
+	assert.Equal(t, "", board.Winner())
+
+	// and the variant is still in play
+	res, err := board.Play("A2")
+	require.NoError(t, err)
+	assert.Equal(t, blackPlayer, res.Winner())
+}
@@ -0,0 +1,253 @@
+package gogo
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+var (
+	// ErrCodeTaken is returned when reserving a code that's already in
+	// use.
+	ErrCodeTaken = errors.New("code is already in use")
+	// ErrCodesExhausted is returned when a CodeGenerator can't find a
+	// free code.
+	ErrCodesExhausted = errors.New("no free codes left")
+)
+
+// UnambiguousAlphabet is the letters and digits that can't be mistaken
+// for each other when read aloud or copied by hand: there's no 0 or O, 1,
+// I or L.
+const UnambiguousAlphabet string = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// maxCodeAttempts is how many random codes New tries before giving up.
+const maxCodeAttempts int = 100
+
+// DefaultCodes is where boards get their Code from, unless they're
+// created WithCodes. Every code a board is given, loaded with or created
+// WithCodes is marked as in use here, so no two boards in a program
+// share one. Deleting a game from a MemoryStore or FileStore Releases
+// its code; Release the codes of other games once they're finished with.
+var DefaultCodes = &CodeGenerator{
+	length:   codeLen,
+	alphabet: UnambiguousAlphabet,
+	source:   crand.Reader,
+	active:   map[string]bool{},
+}
+
+// CodeGenerator hands out game codes that are unique among the codes it
+// knows are in use. By default codes are codeLen characters from
+// UnambiguousAlphabet, drawn from crypto/rand. It's safe for concurrent
+// use.
+type CodeGenerator struct {
+	mu       sync.Mutex
+	length   int
+	alphabet string
+	source   io.Reader
+	active   map[string]bool
+}
+
+// CodeOption configures a CodeGenerator when it's created.
+type CodeOption func(*CodeGenerator) error
+
+// WithCodeLength sets how many characters codes have, from 1 to 32.
+func WithCodeLength(n int) CodeOption {
+	return func(g *CodeGenerator) error {
+		if n < 1 || n > 32 {
+			return fmt.Errorf("code length of %v isn't between 1 and 32", n)
+		}
+		g.length = n
+		return nil
+	}
+}
+
+// WithAlphabet sets the characters codes are made from. They have to be
+// ASCII letters or digits, so codes are safe to use in file names and
+// URLs, with at least two and no repeats.
+func WithAlphabet(alphabet string) CodeOption {
+	return func(g *CodeGenerator) error {
+		if len(alphabet) < 2 {
+			return fmt.Errorf("alphabet %q needs at least two characters", alphabet)
+		}
+		seen := map[rune]bool{}
+		for _, c := range alphabet {
+			if !isCodeChar(c) {
+				return fmt.Errorf("alphabet %q has %q, which isn't a letter or digit", alphabet, c)
+			}
+			if seen[c] {
+				return fmt.Errorf("alphabet %q has %q more than once", alphabet, c)
+			}
+			seen[c] = true
+		}
+		g.alphabet = alphabet
+		return nil
+	}
+}
+
+// WithCodeSource sets where the random bytes for codes come from, instead
+// of crypto/rand. A *rand.Rand from math/rand works, for repeatable
+// codes.
+func WithCodeSource(r io.Reader) CodeOption {
+	return func(g *CodeGenerator) error {
+		if r == nil {
+			return fmt.Errorf("code source can't be nil")
+		}
+		g.source = r
+		return nil
+	}
+}
+
+// isCodeChar reports whether c is an ASCII letter or digit.
+func isCodeChar(c rune) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// NewCodeGenerator creates a generator with no codes in use.
+func NewCodeGenerator(opts ...CodeOption) (*CodeGenerator, error) {
+	g := &CodeGenerator{
+		length:   codeLen,
+		alphabet: UnambiguousAlphabet,
+		source:   crand.Reader,
+		active:   map[string]bool{},
+	}
+	for _, opt := range opts {
+		if err := opt(g); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// New returns a code that isn't in use, and marks it as used until it's
+// Released.
+func (g *CodeGenerator) New() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if float64(len(g.active)) >= math.Pow(float64(len(g.alphabet)), float64(g.length)) {
+		return "", ErrCodesExhausted
+	}
+
+	for i := 0; i < maxCodeAttempts; i++ {
+		code, err := g.random(g.length)
+		if err != nil {
+			return "", fmt.Errorf("unable to generate a code: %w", err)
+		}
+		if !g.active[code] {
+			g.active[code] = true
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("tried %v codes: %w", maxCodeAttempts, ErrCodesExhausted)
+}
+
+// newOfLength returns a code of n characters that isn't in use, and
+// marks it as used, for when codes of the usual length run out.
+func (g *CodeGenerator) newOfLength(n int) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	code, err := g.random(n)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate a code: %w", err)
+	}
+	if g.active[code] {
+		return "", fmt.Errorf("can't use %q: %w", code, ErrCodeTaken)
+	}
+	g.active[code] = true
+	return code, nil
+}
+
+// random returns a code of n uniformly chosen characters.
+func (g *CodeGenerator) random(n int) (string, error) {
+	// bytes at or above limit are skipped, so every character is
+	// equally likely
+	limit := 256 - 256%len(g.alphabet)
+	out := make([]byte, 0, n)
+	buf := make([]byte, n)
+	for len(out) < n {
+		if _, err := io.ReadFull(g.source, buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < limit && len(out) < n {
+				out = append(out, g.alphabet[int(b)%len(g.alphabet)])
+			}
+		}
+	}
+	return string(out), nil
+}
+
+// Reserve marks a code, like one from a game that was loaded, as in use.
+// It returns ErrCodeTaken if it already is.
+func (g *CodeGenerator) Reserve(code string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if code == "" {
+		return fmt.Errorf("can't reserve an empty code")
+	}
+	if g.active[code] {
+		return fmt.Errorf("can't reserve %q: %w", code, ErrCodeTaken)
+	}
+	g.active[code] = true
+	return nil
+}
+
+// ReserveStored marks the code of every game saved in store as in use.
+func (g *CodeGenerator) ReserveStored(store Store) error {
+	codes, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, code := range codes {
+		g.active[code] = true
+	}
+	return nil
+}
+
+// use marks a code as in use, whether or not it already was.
+func (g *CodeGenerator) use(code string) {
+	if code == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.active[code] = true
+}
+
+// Release marks a code as free again, once its game is finished with.
+func (g *CodeGenerator) Release(code string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.active, code)
+}
+
+// InUse reports whether a code is in use.
+func (g *CodeGenerator) InUse(code string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.active[code]
+}
+
+// WithCodes gives the board a new code from g when it's created, instead
+// of one from DefaultCodes. The code is reserved even if a later option
+// or NewBoard itself fails, so it's best passed last; to be sure none
+// are lost, use g.New and Release directly.
+func WithCodes(g *CodeGenerator) Option {
+	return func(b *Board) error {
+		code, err := g.New()
+		if err != nil {
+			return err
+		}
+		DefaultCodes.use(code)
+		b.code = code
+		return nil
+	}
+}
@@ -0,0 +1,209 @@
+package gogo
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeGeneratorOptions(t *testing.T) {
+	tests := []struct {
+		opts      []CodeOption
+		expectErr bool
+	}{
+		{},
+		{opts: []CodeOption{WithCodeLength(8), WithAlphabet("abc123")}},
+		{opts: []CodeOption{WithCodeLength(0)}, expectErr: true},
+		{opts: []CodeOption{WithCodeLength(33)}, expectErr: true},
+		{opts: []CodeOption{WithAlphabet("A")}, expectErr: true},
+		{opts: []CodeOption{WithAlphabet("ABA")}, expectErr: true},
+		{opts: []CodeOption{WithAlphabet("AB-")}, expectErr: true},
+		{opts: []CodeOption{WithAlphabet("ABÉ")}, expectErr: true},
+		{opts: []CodeOption{WithCodeSource(nil)}, expectErr: true},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			_, err := NewCodeGenerator(tt.opts...)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCodeGeneratorNew(t *testing.T) {
+	tests := []struct {
+		length   int
+		alphabet string
+	}{
+		{length: codeLen, alphabet: UnambiguousAlphabet},
+		{length: 6, alphabet: "0123456789"},
+		{length: 12, alphabet: "xyz"},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			g, err := NewCodeGenerator(
+				WithCodeLength(tt.length),
+				WithAlphabet(tt.alphabet),
+				WithCodeSource(rand.New(rand.NewSource(1))),
+			)
+			require.NoError(t, err)
+
+			seen := map[string]bool{}
+			for i := 0; i < 50; i++ {
+				code, err := g.New()
+				require.NoError(t, err)
+				assert.Len(t, code, tt.length)
+				for _, c := range code {
+					assert.Contains(t, tt.alphabet, string(c))
+				}
+				assert.False(t, seen[code], "code %v repeated", code)
+				assert.True(t, g.InUse(code))
+				seen[code] = true
+			}
+		})
+	}
+}
+
+func TestCodeGeneratorRepeatable(t *testing.T) {
+	codes := func() []string {
+		g, err := NewCodeGenerator(WithCodeSource(rand.New(rand.NewSource(7))))
+		require.NoError(t, err)
+		var out []string
+		for i := 0; i < 5; i++ {
+			code, err := g.New()
+			require.NoError(t, err)
+			out = append(out, code)
+		}
+		return out
+	}
+	assert.Equal(t, codes(), codes())
+}
+
+func TestCodeGeneratorExhausted(t *testing.T) {
+	g, err := NewCodeGenerator(WithCodeLength(1), WithAlphabet("AB"))
+	require.NoError(t, err)
+
+	first, err := g.New()
+	require.NoError(t, err)
+	second, err := g.New()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"A", "B"}, []string{first, second})
+
+	_, err = g.New()
+	assert.True(t, errors.Is(err, ErrCodesExhausted))
+
+	// releasing a code makes it available again
+	g.Release(first)
+	assert.False(t, g.InUse(first))
+	code, err := g.New()
+	require.NoError(t, err)
+	assert.Equal(t, first, code)
+}
+
+func TestCodeGeneratorReserve(t *testing.T) {
+	g, err := NewCodeGenerator(WithCodeLength(1), WithAlphabet("AB"))
+	require.NoError(t, err)
+
+	require.NoError(t, g.Reserve("A"))
+	assert.True(t, errors.Is(g.Reserve("A"), ErrCodeTaken))
+	assert.Error(t, g.Reserve(""))
+
+	code, err := g.New()
+	require.NoError(t, err)
+	assert.Equal(t, "B", code)
+}
+
+func TestCodeGeneratorReserveStored(t *testing.T) {
+	store := NewMemoryStore()
+	board, err := NewBoard(9)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(&board))
+
+	g, err := NewCodeGenerator()
+	require.NoError(t, err)
+	require.NoError(t, g.ReserveStored(store))
+	assert.True(t, g.InUse(board.Code()))
+	assert.True(t, errors.Is(g.Reserve(board.Code()), ErrCodeTaken))
+}
+
+func TestWithCodes(t *testing.T) {
+	g, err := NewCodeGenerator(WithCodeLength(6), WithAlphabet("abcdef"))
+	require.NoError(t, err)
+
+	board, err := NewBoard(9, WithCodes(g))
+	require.NoError(t, err)
+	assert.Len(t, board.Code(), 6)
+	assert.Empty(t, strings.Trim(board.Code(), "abcdef"))
+	assert.True(t, g.InUse(board.Code()))
+
+	full, err := NewCodeGenerator(WithCodeLength(1), WithAlphabet("AB"))
+	require.NoError(t, err)
+	require.NoError(t, full.Reserve("A"))
+	require.NoError(t, full.Reserve("B"))
+	_, err = NewBoard(9, WithCodes(full))
+	assert.True(t, errors.Is(err, ErrCodesExhausted))
+}
+
+func TestLobbyGameCodes(t *testing.T) {
+	g, err := NewCodeGenerator(WithCodeLength(1), WithAlphabet("AB"))
+	require.NoError(t, err)
+	l, store, _ := testLobby(t, WithGameCodes(g))
+
+	settings := GameSettings{Size: 9}
+	var codes []string
+	for _, pair := range [][2]Player{{alice, bob}, {carol, alice}} {
+		_, id, err := l.Seek(pair[0], settings, 1500, 100)
+		require.NoError(t, err)
+		require.NotEmpty(t, id)
+		board, _, err := l.Seek(pair[1], settings, 1500, 100)
+		require.NoError(t, err)
+		checkGame(t, store, board, pair[0], pair[1])
+		codes = append(codes, board.Code())
+	}
+	assert.ElementsMatch(t, []string{"A", "B"}, codes)
+
+	// a lobby on the same store doesn't reuse the saved codes
+	other, err := NewCodeGenerator(WithCodeLength(1), WithAlphabet("AB"))
+	require.NoError(t, err)
+	_, err = NewLobby(store, WithGameCodes(other))
+	require.NoError(t, err)
+	_, err = other.New()
+	assert.True(t, errors.Is(err, ErrCodesExhausted))
+}
+
+// failingStore is a store that can't save games.
+type failingStore struct {
+	*MemoryStore
+}
+
+// Save always fails.
+func (failingStore) Save(*Board) error {
+	return errors.New("disk full")
+}
+
+func TestLobbyReleasesCodesOfGamesThatDontStart(t *testing.T) {
+	g, err := NewCodeGenerator(WithCodeLength(1), WithAlphabet("AB"))
+	require.NoError(t, err)
+	l, err := NewLobby(failingStore{NewMemoryStore()}, WithGameCodes(g))
+	require.NoError(t, err)
+
+	settings := GameSettings{Size: 9}
+	_, _, err = l.Seek(alice, settings, 1500, 100)
+	require.NoError(t, err)
+	_, _, err = l.Seek(bob, settings, 1500, 100)
+	require.Error(t, err)
+	assert.False(t, g.InUse("A"))
+	assert.False(t, g.InUse("B"))
+}
@@ -0,0 +1,149 @@
+package gogo
+
+import "fmt"
+
+// ConditionalMove is a move queued in advance: if the opponent plays If,
+// Then is played straight away as the answer, and Next holds the moves
+// queued after that.
+type ConditionalMove struct {
+	If   Point             `json:"if"`
+	Then Point             `json:"then"`
+	Next []ConditionalMove `json:"next,omitempty"`
+}
+
+// AddConditionalMoves queues a line of moves for a player ( "black",
+// "white", ... ) while they wait for their turn, alternating between a
+// move the opponent might play and the answer to it, in A1 notation:
+//
+//	b.AddConditionalMoves("white", "D4", "Q16", "C3", "R4")
+//
+// answers D4 with Q16, and then C3 with R4. Lines that start the same way
+// share their moves, so several lines make a tree of answers. Queued
+// moves are played as soon as the opponent places the stone they answer,
+// and all of a player's queued moves are cancelled when the opponent
+// plays anything else, passes, or a move is undone.
+func (b *Board) AddConditionalMoves(player string, line ...string) error {
+	if !b.isPlaying(player) {
+		return fmt.Errorf("unknown player %q", player)
+	}
+	if b.GameOver() {
+		return fmt.Errorf("can't queue moves, %w", ErrGameOver)
+	}
+	if player == b.currentPlayer {
+		return fmt.Errorf("can't queue moves for %v, it's their turn", player)
+	}
+	if len(line) == 0 || len(line)%2 == 1 {
+		return fmt.Errorf("conditional moves come in pairs, got %v moves", len(line))
+	}
+
+	points := make([]Point, 0, len(line))
+	for _, input := range line {
+		p, err := b.parsePoint(input)
+		if err != nil {
+			return err
+		}
+		if _, err := b.pointToIdx(p); err != nil {
+			return err
+		}
+		points = append(points, p)
+	}
+
+	colour := playerToColour(player)
+	tree := copyConditional(b.conditional[colour])
+	level := &tree
+	for i := 0; i < len(points); i += 2 {
+		at := -1
+		for j, m := range *level {
+			if m.If == points[i] {
+				at = j
+			}
+		}
+		if at < 0 {
+			*level = append(*level, ConditionalMove{If: points[i], Then: points[i+1]})
+			at = len(*level) - 1
+		} else if (*level)[at].Then != points[i+1] {
+			return fmt.Errorf("%v is already answered with %v", line[i], b.pointName((*level)[at].Then))
+		}
+		level = &(*level)[at].Next
+	}
+
+	if b.conditional == nil {
+		b.conditional = map[rune][]ConditionalMove{}
+	}
+	b.conditional[colour] = tree
+	return nil
+}
+
+// AddConditionalMovesAs is AddConditionalMoves, for the player with the
+// given ID.
+func (b *Board) AddConditionalMovesAs(id string, line ...string) error {
+	colour, ok := b.ColourOf(id)
+	if !ok {
+		return fmt.Errorf("player %q isn't seated in this game", id)
+	}
+	return b.AddConditionalMoves(colour, line...)
+}
+
+// ConditionalMoves returns the moves a player has queued.
+func (b Board) ConditionalMoves(player string) []ConditionalMove {
+	return copyConditional(b.conditional[playerToColour(player)])
+}
+
+// CancelConditionalMoves cancels all of a player's queued moves.
+func (b *Board) CancelConditionalMoves(player string) {
+	delete(b.conditional, playerToColour(player))
+}
+
+// copyConditional ...
+func copyConditional(moves []ConditionalMove) []ConditionalMove {
+	if moves == nil {
+		return nil
+	}
+	out := make([]ConditionalMove, len(moves))
+	for i, m := range moves {
+		out[i] = ConditionalMove{If: m.If, Then: m.Then, Next: copyConditional(m.Next)}
+	}
+	return out
+}
+
+// answer plays the queued answer of the player whose turn it now is to
+// the move just played at p, or cancels their queued moves if they
+// didn't expect it. p is nil for a pass.
+func (b *Board) answer(p *Point) {
+	colour := b.nextPiece
+	tree, ok := b.conditional[colour]
+	if !ok {
+		return
+	}
+	delete(b.conditional, colour)
+	if b.GameOver() {
+		return
+	}
+
+	var next *ConditionalMove
+	for i, m := range tree {
+		if p != nil && m.If == *p {
+			next = &tree[i]
+		}
+	}
+	if next == nil {
+		b.log(LevelInfo, logConditional, "player", b.currentPlayer, "cancelled", len(tree))
+		return
+	}
+
+	// the rest of the tree is put back before the answer is played, so
+	// that if the opponent's queued moves answer it in turn, this
+	// player's can answer them
+	if len(next.Next) > 0 {
+		if b.conditional == nil {
+			b.conditional = map[rune][]ConditionalMove{}
+		}
+		b.conditional[colour] = next.Next
+	}
+	player := b.currentPlayer
+	b.log(LevelInfo, logConditional, "player", player, "point", b.pointName(next.Then))
+	if _, err := b.Place(next.Then); err != nil {
+		delete(b.conditional, colour)
+		b.log(LevelWarn, logConditional, "player", player, "point", b.pointName(next.Then), "error", err)
+	}
+}
@@ -0,0 +1,195 @@
+package gogo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalMoves(t *testing.T) {
+	tests := []struct {
+		lines         [][]string
+		actions       []string
+		expectBoard   []string
+		expectPlayer  string
+		expectPending int
+		expectLog     []string
+	}{
+		{
+			// both queued answers are played
+			lines:        [][]string{{"C3", "G7", "C7", "G3"}},
+			actions:      []string{"C3", "C7"},
+			expectBoard:  []string{"C3", "G7", "C7", "G3"},
+			expectPlayer: blackPlayer,
+			expectLog:    []string{"place", "conditional", "place", "place", "conditional", "place"},
+		},
+		{
+			// the opponent deviates on their second move
+			lines:        [][]string{{"C3", "G7", "C7", "G3"}},
+			actions:      []string{"C3", "D4"},
+			expectBoard:  []string{"C3", "G7", "D4"},
+			expectPlayer: whitePlayer,
+			expectLog:    []string{"place", "conditional", "place", "place", "conditional"},
+		},
+		{
+			// lines that start differently branch
+			lines:         [][]string{{"C3", "G7", "C7", "G3"}, {"D4", "F6"}},
+			actions:       []string{"D4"},
+			expectBoard:   []string{"D4", "F6"},
+			expectPlayer:  blackPlayer,
+			expectPending: 0,
+			expectLog:     []string{"place", "conditional", "place"},
+		},
+		{
+			lines:         [][]string{{"C3", "G7", "C7", "G3"}, {"C3", "G7", "D4", "F6"}},
+			actions:       []string{"C3"},
+			expectBoard:   []string{"C3", "G7"},
+			expectPlayer:  blackPlayer,
+			expectPending: 2,
+			expectLog:     []string{"place", "conditional", "place"},
+		},
+		{
+			// passing cancels everything
+			lines:        [][]string{{"C3", "G7"}},
+			actions:      []string{"pass"},
+			expectPlayer: whitePlayer,
+			expectLog:    []string{"pass", "conditional"},
+		},
+		{
+			// an answer that can't be played is cancelled
+			lines:        [][]string{{"C3", "G7", "E5", "E5"}},
+			actions:      []string{"C3", "E5"},
+			expectBoard:  []string{"C3", "G7", "E5"},
+			expectPlayer: whitePlayer,
+			expectLog:    []string{"place", "conditional", "place", "place", "conditional", "reject", "conditional"},
+		},
+		{
+			// taking back a move cancels everything
+			lines:        [][]string{{"C3", "G7", "C7", "G3"}},
+			actions:      []string{"C3", "undo"},
+			expectBoard:  []string{"C3"},
+			expectPlayer: whitePlayer,
+			expectLog:    []string{"place", "conditional", "place", "conditional"},
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			logger := &recordLogger{level: LevelInfo}
+			board, err := NewBoard(9, WithLogger(logger))
+			require.NoError(t, err)
+			for _, line := range tt.lines {
+				require.NoError(t, board.AddConditionalMoves(whitePlayer, line...))
+			}
+
+			require.NoError(t, play(&board, tt.actions))
+
+			expect, err := NewBoard(9)
+			require.NoError(t, err)
+			require.NoError(t, play(&expect, tt.expectBoard))
+			assert.Equal(t, expect.String(), board.String())
+			assert.Equal(t, tt.expectPlayer, board.CurrentPlayer())
+			assert.Len(t, board.ConditionalMoves(whitePlayer), tt.expectPending)
+			assert.Equal(t, tt.expectLog, logger.messages())
+
+			// the answers are ordinary moves in the event log
+			rebuilt, err := Rebuild(9, board.Events())
+			require.NoError(t, err)
+			assert.Equal(t, board.String(), rebuilt.String())
+		})
+	}
+}
+
+func TestUndoCancelsConditionalMoves(t *testing.T) {
+	logger := &recordLogger{level: LevelInfo}
+	board, err := NewBoard(9, WithLogger(logger))
+	require.NoError(t, err)
+	require.NoError(t, play(&board, []string{"D4"}))
+	require.NoError(t, board.AddConditionalMoves(blackPlayer, "E5", "F6", "C3", "G7"))
+
+	// black's answers were queued for after D4, which isn't there any more
+	require.NoError(t, board.Undo())
+	assert.Empty(t, board.ConditionalMoves(blackPlayer))
+	assert.Equal(t, []string{"place", "conditional"}, logger.messages())
+
+	require.NoError(t, play(&board, []string{"C3", "E5"}))
+	expect, err := NewBoard(9)
+	require.NoError(t, err)
+	require.NoError(t, play(&expect, []string{"C3", "E5"}))
+	assert.Equal(t, expect.String(), board.String())
+}
+
+func TestAddConditionalMovesErrors(t *testing.T) {
+	tests := []struct {
+		player string
+		line   []string
+	}{
+		{player: blackPlayer, line: []string{"C3", "G7"}},
+		{player: "red", line: []string{"C3", "G7"}},
+		{player: whitePlayer},
+		{player: whitePlayer, line: []string{"C3", "G7", "C7"}},
+		{player: whitePlayer, line: []string{"C3", "Z7"}},
+		{player: whitePlayer, line: []string{"C3", "nope"}},
+		// C3 is already answered with G7
+		{player: whitePlayer, line: []string{"C3", "G3"}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			board, err := NewBoard(9)
+			require.NoError(t, err)
+			require.NoError(t, board.AddConditionalMoves(whitePlayer, "C3", "G7", "C7", "G3"))
+
+			assert.Error(t, board.AddConditionalMoves(tt.player, tt.line...))
+			assert.Equal(t, []ConditionalMove{{
+				If:   Point{X: 2, Y: 2},
+				Then: Point{X: 6, Y: 6},
+				Next: []ConditionalMove{{If: Point{X: 2, Y: 6}, Then: Point{X: 6, Y: 2}}},
+			}}, board.ConditionalMoves(whitePlayer), "failed lines don't change anything")
+		})
+	}
+
+	board, err := NewBoard(9)
+	require.NoError(t, err)
+	require.NoError(t, board.Resign(blackPlayer))
+	assert.ErrorIs(t, board.AddConditionalMoves(whitePlayer, "C3", "G7"), ErrGameOver)
+}
+
+func TestConditionalMovesAs(t *testing.T) {
+	board, err := NewBoard(9, WithSeat(blackPlayer, alice), WithSeat(whitePlayer, bob))
+	require.NoError(t, err)
+
+	assert.Error(t, board.AddConditionalMovesAs(carol.ID, "C3", "G7"))
+	assert.Error(t, board.AddConditionalMovesAs(alice.ID, "C3", "G7"), "it's alice's turn")
+	require.NoError(t, board.AddConditionalMovesAs(bob.ID, "C3", "G7"))
+	assert.Len(t, board.ConditionalMoves(whitePlayer), 1)
+
+	board.CancelConditionalMoves(whitePlayer)
+	assert.Empty(t, board.ConditionalMoves(whitePlayer))
+}
+
+func TestConditionalMovesStore(t *testing.T) {
+	board, err := NewBoard(9)
+	require.NoError(t, err)
+	require.NoError(t, play(&board, []string{"E5"}))
+	require.NoError(t, board.AddConditionalMoves(blackPlayer, "C3", "G7", "C7", "G3"))
+
+	store := NewMemoryStore()
+	require.NoError(t, store.Save(&board))
+	loaded, err := store.Load(board.Code())
+	require.NoError(t, err)
+	assert.Equal(t, board.ConditionalMoves(blackPlayer), loaded.ConditionalMoves(blackPlayer))
+
+	// the loaded game answers just the same
+	require.NoError(t, play(&loaded, []string{"C3"}))
+	expect, err := NewBoard(9)
+	require.NoError(t, err)
+	require.NoError(t, play(&expect, []string{"E5", "C3", "G7"}))
+	assert.Equal(t, expect.String(), loaded.String())
+	assert.Len(t, loaded.ConditionalMoves(blackPlayer), 1)
+}
@@ -0,0 +1,720 @@
+package gogo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLowTime is how close to their deadline a correspondence player
+// is warned they're running out of time, unless it's set WithLowTime.
+const DefaultLowTime = 24 * time.Hour
+
+// day is how long a day of a DayControl is.
+const day = 24 * time.Hour
+
+// DayControl is a correspondence time control. Each player has
+// DaysPerMove days for every move. With WeekendPause, clocks stop from
+// the start of Saturday until the end of Sunday. Each player can also
+// stop their clock in the game for up to VacationDays days, by going on
+// vacation.
+type DayControl struct {
+	DaysPerMove  int  `json:"days_per_move"`
+	WeekendPause bool `json:"weekend_pause,omitempty"`
+	VacationDays int  `json:"vacation_days,omitempty"`
+}
+
+// String formats the time control like "3 days per move, weekends off,
+// 10 days vacation".
+func (c DayControl) String() string {
+	out := fmt.Sprintf("%v days per move", c.DaysPerMove)
+	if c.WeekendPause {
+		out += ", weekends off"
+	}
+	if c.VacationDays > 0 {
+		out += fmt.Sprintf(", %v days vacation", c.VacationDays)
+	}
+	return out
+}
+
+// NotificationType is why a player is being sent a Notification.
+type NotificationType string
+
+const (
+	// NotifyTurn is sent when it's Player's turn.
+	NotifyTurn NotificationType = "turn"
+	// NotifyLowTime is sent once a turn, when Player's deadline is
+	// close.
+	NotifyLowTime NotificationType = "low time"
+)
+
+// Notification is a message for a correspondence player, telling them
+// they need to play in Game before Deadline.
+type Notification struct {
+	Type     NotificationType
+	Game     string
+	Player   Player
+	Colour   string
+	Deadline time.Time
+}
+
+// Notifier delivers notifications to players, by email, push message or
+// whatever else. Notify is called while the Correspondence is locked, so
+// it should hand slow deliveries off rather than wait for them, and any
+// retrying is up to it.
+type Notifier interface {
+	Notify(n Notification)
+}
+
+// NotifierFunc adapts a function to a Notifier.
+type NotifierFunc func(n Notification)
+
+// Notify ...
+func (f NotifierFunc) Notify(n Notification) {
+	f(n)
+}
+
+// Clock is where the clock of a correspondence game stands for the
+// player whose turn it is.
+type Clock struct {
+	Game   string
+	Colour string
+	Player Player
+	// Remaining is the time left for the move, not counting weekends
+	// off or vacation.
+	Remaining time.Duration
+	// Deadline is when the player runs out of time, if they stay on
+	// vacation as long as they can.
+	Deadline time.Time
+	// OnVacation is true while the clock is stopped for the player's
+	// vacation, and Vacation is how much of it they have left.
+	OnVacation bool
+	Vacation   time.Duration
+}
+
+// Correspondence runs games played over days rather than in one sitting.
+// Games are added with a DayControl and their moves made through it, so
+// that each player's clock starts when it's their turn. Check times out
+// players who run out of time and warns the ones who are about to, so
+// it should be called regularly. Games are loaded from and saved to its
+// Store with their clocks, so a Correspondence created on the same Store
+// carries on where the last one left off. It's safe for concurrent use.
+type Correspondence struct {
+	mu        sync.Mutex
+	store     Store
+	notifier  Notifier
+	logger    Logger
+	location  *time.Location
+	lowTime   time.Duration
+	now       func() time.Time
+	games     map[string]*correspondenceGame
+	vacations map[string]bool
+}
+
+// correspondenceGame is the clock of a game, for the player whose turn
+// it is. used is the time they've used since their turn started, up to
+// since.
+type correspondenceGame struct {
+	control  DayControl
+	colour   string
+	player   Player
+	since    time.Time
+	used     time.Duration
+	vacation map[string]time.Duration
+	warned   bool
+}
+
+// clockRecord is the clock of a correspondence game as it's saved with
+// the game. Away is the IDs of its seated players who are on vacation.
+type clockRecord struct {
+	Control  DayControl               `json:"control"`
+	Since    time.Time                `json:"since"`
+	Used     time.Duration            `json:"used,omitempty"`
+	Vacation map[string]time.Duration `json:"vacation,omitempty"`
+	Warned   bool                     `json:"warned,omitempty"`
+	Away     []string                 `json:"away,omitempty"`
+}
+
+// CorrespondenceOption configures a Correspondence when it's created.
+type CorrespondenceOption func(*Correspondence) error
+
+// WithNotifier sends players a Notification when it's their turn and
+// when they're running low on time. By default nobody is notified.
+func WithNotifier(n Notifier) CorrespondenceOption {
+	return func(c *Correspondence) error {
+		if n == nil {
+			return fmt.Errorf("notifier can't be nil")
+		}
+		c.notifier = n
+		return nil
+	}
+}
+
+// logRestore is logged at LevelWarn, with the keys game and error, for
+// each game in the store that couldn't be loaded when a Correspondence
+// is created.
+const logRestore = "restore"
+
+// WithCorrespondenceLogger reports games in the store that couldn't be
+// loaded to l. By default they're skipped without a word.
+func WithCorrespondenceLogger(l Logger) CorrespondenceOption {
+	return func(c *Correspondence) error {
+		if l == nil {
+			return fmt.Errorf("logger can't be nil")
+		}
+		c.logger = l
+		return nil
+	}
+}
+
+// WithTimeZone sets where weekends are, for games that pause for them.
+// By default it's UTC.
+func WithTimeZone(loc *time.Location) CorrespondenceOption {
+	return func(c *Correspondence) error {
+		if loc == nil {
+			return fmt.Errorf("time zone can't be nil")
+		}
+		c.location = loc
+		return nil
+	}
+}
+
+// WithLowTime sets how close to their deadline players are warned
+// they're running out of time.
+func WithLowTime(d time.Duration) CorrespondenceOption {
+	return func(c *Correspondence) error {
+		if d <= 0 {
+			return fmt.Errorf("low time of %v isn't positive", d)
+		}
+		c.lowTime = d
+		return nil
+	}
+}
+
+// NewCorrespondence creates a Correspondence that loads and saves games
+// with store, carrying on with every unfinished game saved there with a
+// clock. Games that can't be loaded are skipped, and reported to the
+// logger set WithCorrespondenceLogger.
+func NewCorrespondence(store Store, opts ...CorrespondenceOption) (*Correspondence, error) {
+	if store == nil {
+		return nil, fmt.Errorf("correspondence needs a store for its games")
+	}
+
+	c := &Correspondence{
+		store:     store,
+		notifier:  NotifierFunc(func(Notification) {}),
+		location:  time.UTC,
+		lowTime:   DefaultLowTime,
+		now:       time.Now,
+		games:     map[string]*correspondenceGame{},
+		vacations: map[string]bool{},
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.restore(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// restore picks up the clocks of the unfinished games in the store.
+func (c *Correspondence) restore() error {
+	codes, err := c.store.List()
+	if err != nil {
+		return fmt.Errorf("unable to restore correspondence games: %w", err)
+	}
+	for _, code := range codes {
+		b, err := c.store.Load(code)
+		if err != nil {
+			// one bad game mustn't stop every other game being played
+			if c.logger != nil {
+				c.logger.Log(LevelWarn, logRestore, "game", code, "error", err)
+			}
+			continue
+		}
+		r := b.clock
+		if r == nil || b.GameOver() {
+			continue
+		}
+
+		g := &correspondenceGame{
+			control:  r.Control,
+			colour:   b.CurrentPlayer(),
+			since:    r.Since,
+			used:     r.Used,
+			vacation: map[string]time.Duration{},
+			warned:   r.Warned,
+		}
+		g.player, _ = b.Seat(g.colour)
+		for colour, d := range r.Vacation {
+			g.vacation[colour] = d
+		}
+		for _, id := range r.Away {
+			c.vacations[id] = true
+		}
+		c.games[code] = g
+	}
+	return nil
+}
+
+// save saves b along with the clock of g, or with no clock if g is nil.
+func (c *Correspondence) save(b *Board, g *correspondenceGame) error {
+	b.clock = nil
+	if g != nil {
+		r := &clockRecord{Control: g.control, Since: g.since, Used: g.used, Warned: g.warned}
+		for colour, d := range g.vacation {
+			if r.Vacation == nil {
+				r.Vacation = map[string]time.Duration{}
+			}
+			r.Vacation[colour] = d
+		}
+		for _, colour := range b.Players() {
+			if p, ok := b.Seat(colour); ok && c.vacations[p.ID] {
+				r.Away = append(r.Away, p.ID)
+			}
+		}
+		b.clock = r
+	}
+	return c.store.Save(b)
+}
+
+// Add saves a game whose players are all seated and starts the clock of
+// the player whose turn it is, notifying them. It refuses a game whose
+// code is already used by a game in the store, rather than replace it.
+func (c *Correspondence) Add(b *Board, control DayControl) error {
+	if control.DaysPerMove < 1 {
+		return fmt.Errorf("correspondence games need at least a day per move, not %v", control.DaysPerMove)
+	}
+	if control.VacationDays < 0 {
+		return fmt.Errorf("vacation of %v days is negative", control.VacationDays)
+	}
+	if b.GameOver() {
+		return fmt.Errorf("can't add game %q, %w", b.Code(), ErrGameOver)
+	}
+	for _, colour := range b.Players() {
+		if _, ok := b.Seat(colour); !ok {
+			return fmt.Errorf("can't add game %q, nobody is seated at %v", b.Code(), colour)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	code := b.Code()
+	if _, ok := c.games[code]; ok {
+		return fmt.Errorf("game %q is already being played", code)
+	}
+	_, err := c.store.Load(code)
+	if err == nil {
+		return fmt.Errorf("can't add game %q, a game with that code is already saved: %w", code, ErrCodeTaken)
+	}
+	if !errors.Is(err, ErrGameNotFound) {
+		return err
+	}
+	now := c.now()
+	g := &correspondenceGame{control: control, vacation: map[string]time.Duration{}}
+	c.startTurn(g, b, now)
+	if err := c.save(b, g); err != nil {
+		return err
+	}
+	c.games[code] = g
+	c.notify(NotifyTurn, code, g, now)
+	return nil
+}
+
+// Games returns the codes of the games being played, in order.
+func (c *Correspondence) Games() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]string, 0, len(c.games))
+	for code := range c.games {
+		out = append(out, code)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Clock returns where the clock of the game with the given code stands.
+func (c *Correspondence) Clock(code string) (Clock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, ok := c.games[code]
+	if !ok {
+		return Clock{}, fmt.Errorf("can't find clock for %q: %w", code, ErrGameNotFound)
+	}
+	now := c.now()
+	c.settle(g, now)
+
+	out := Clock{
+		Game:       code,
+		Colour:     g.colour,
+		Player:     g.player,
+		Remaining:  g.remaining(),
+		OnVacation: c.paused(g),
+		Vacation:   g.vacationLeft(),
+	}
+	out.Deadline = c.deadline(g, now)
+	return out, nil
+}
+
+// Play is PlayAs in the game with the given code, restarting the clock
+// for whoever plays next. Playing after running out of time, even
+// before Check notices, loses the game on time.
+func (c *Correspondence) Play(code, id, input string) (Result, error) {
+	return c.move(code, func(b *Board) (Result, error) {
+		return b.PlayAs(id, input)
+	})
+}
+
+// Pass is PassAs in the game with the given code, restarting the clock
+// for whoever plays next.
+func (c *Correspondence) Pass(code, id string) (Result, error) {
+	return c.move(code, func(b *Board) (Result, error) {
+		return b.PassAs(id)
+	})
+}
+
+// Resign is ResignAs in the game with the given code, which stops its
+// clock.
+func (c *Correspondence) Resign(code, id string) error {
+	_, err := c.move(code, func(b *Board) (Result, error) {
+		return Result{}, b.ResignAs(id)
+	})
+	return err
+}
+
+// AddConditionalMoves is AddConditionalMovesAs in the game with the given
+// code, saving the queued moves with it. Answers played from the queue
+// restart the clock like any other move.
+func (c *Correspondence) AddConditionalMoves(code, id string, line ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, ok := c.games[code]
+	if !ok {
+		return fmt.Errorf("can't queue moves in %q: %w", code, ErrGameNotFound)
+	}
+	over, err := c.expire(code, g, c.now())
+	if err != nil {
+		return err
+	}
+	if over {
+		return fmt.Errorf("can't queue moves in %q, %v ran out of time, %w", code, g.colour, ErrGameOver)
+	}
+
+	b, err := c.store.Load(code)
+	if err != nil {
+		return err
+	}
+	if err := b.AddConditionalMovesAs(id, line...); err != nil {
+		return err
+	}
+	return c.save(&b, g)
+}
+
+// move loads a game, checks the player to move hasn't run out of time,
+// makes the move and saves the game.
+func (c *Correspondence) move(code string, f func(b *Board) (Result, error)) (Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g, ok := c.games[code]
+	if !ok {
+		return Result{}, fmt.Errorf("can't move in %q: %w", code, ErrGameNotFound)
+	}
+	now := c.now()
+	over, err := c.expire(code, g, now)
+	if err != nil {
+		return Result{}, err
+	}
+	if over {
+		return Result{}, fmt.Errorf("can't move in %q, %v ran out of time, %w", code, g.colour, ErrGameOver)
+	}
+
+	b, err := c.store.Load(code)
+	if err != nil {
+		return Result{}, err
+	}
+	moves := b.MoveNumber()
+	res, err := f(&b)
+	if err != nil {
+		return res, err
+	}
+
+	if b.GameOver() {
+		if err := c.save(&b, nil); err != nil {
+			return res, err
+		}
+		delete(c.games, code)
+		return res, nil
+	}
+
+	next := *g
+	moved := b.MoveNumber() != moves
+	if moved {
+		c.startTurn(&next, &b, now)
+	}
+	if err := c.save(&b, &next); err != nil {
+		return res, err
+	}
+	*g = next
+	if moved {
+		c.notify(NotifyTurn, code, g, now)
+	}
+	return res, nil
+}
+
+// Check times out every player who has run out of time, saving their
+// games, and warns the players who are running low.
+func (c *Correspondence) Check() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	codes := make([]string, 0, len(c.games))
+	for code := range c.games {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	now := c.now()
+	for _, code := range codes {
+		g := c.games[code]
+		over, err := c.expire(code, g, now)
+		if err != nil {
+			return err
+		}
+		if over || g.warned || g.remaining() > c.lowTime {
+			continue
+		}
+		b, err := c.store.Load(code)
+		if err != nil {
+			return err
+		}
+		g.warned = true
+		if err := c.save(&b, g); err != nil {
+			g.warned = false
+			return err
+		}
+		c.notify(NotifyLowTime, code, g, now)
+	}
+	return nil
+}
+
+// StartVacation stops the clocks of the player with the given ID, in
+// every game where they have vacation left, until EndVacation. Vacation
+// is only used up in a game while it's their turn.
+func (c *Correspondence) StartVacation(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.vacations[id] {
+		return fmt.Errorf("player %q is already on vacation", id)
+	}
+	c.settleAll(id)
+	c.vacations[id] = true
+	return c.saveAll(id)
+}
+
+// EndVacation restarts the clocks of the player with the given ID.
+func (c *Correspondence) EndVacation(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.vacations[id] {
+		return fmt.Errorf("player %q isn't on vacation", id)
+	}
+	c.settleAll(id)
+	delete(c.vacations, id)
+	return c.saveAll(id)
+}
+
+// OnVacation returns true if the player with the given ID is on
+// vacation.
+func (c *Correspondence) OnVacation(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.vacations[id]
+}
+
+// saveAll saves the clocks of every game the player with the given ID is
+// seated in.
+func (c *Correspondence) saveAll(id string) error {
+	for code, g := range c.games {
+		b, err := c.store.Load(code)
+		if err != nil {
+			return err
+		}
+		if _, ok := b.ColourOf(id); !ok {
+			continue
+		}
+		if err := c.save(&b, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startTurn starts the clock of the player whose turn it is in b.
+func (c *Correspondence) startTurn(g *correspondenceGame, b *Board, now time.Time) {
+	g.colour = b.CurrentPlayer()
+	g.player, _ = b.Seat(g.colour)
+	g.since = now
+	g.used = 0
+	g.warned = false
+}
+
+// notify ...
+func (c *Correspondence) notify(t NotificationType, code string, g *correspondenceGame, now time.Time) {
+	c.notifier.Notify(Notification{
+		Type:     t,
+		Game:     code,
+		Player:   g.player,
+		Colour:   g.colour,
+		Deadline: c.deadline(g, now),
+	})
+}
+
+// expire times out the player whose turn it is if they've run out of
+// time, returning true if they have.
+func (c *Correspondence) expire(code string, g *correspondenceGame, now time.Time) (bool, error) {
+	c.settle(g, now)
+	if g.remaining() > 0 {
+		return false, nil
+	}
+
+	b, err := c.store.Load(code)
+	if err != nil {
+		return false, err
+	}
+	// a game that was finished some other way just stops being timed
+	if !b.GameOver() {
+		if err := b.Timeout(g.colour); err != nil {
+			return false, err
+		}
+	}
+	if err := c.save(&b, nil); err != nil {
+		return false, err
+	}
+	delete(c.games, code)
+	return true, nil
+}
+
+// settleAll brings the clocks of every game where it's the turn of the
+// player with the given ID up to now.
+func (c *Correspondence) settleAll(id string) {
+	now := c.now()
+	for _, g := range c.games {
+		if g.player.ID == id {
+			c.settle(g, now)
+		}
+	}
+}
+
+// settle adds the time the player to move has used since the clock was
+// last settled, spending their vacation first while they're on it.
+// Vacations only start and end at a settled clock, so the player is
+// either on vacation or not for all of it.
+func (c *Correspondence) settle(g *correspondenceGame, now time.Time) {
+	if !now.After(g.since) {
+		return
+	}
+	from := g.since
+	if c.paused(g) {
+		pause := now.Sub(from)
+		if left := g.vacationLeft(); pause > left {
+			pause = left
+		}
+		g.vacation[g.colour] += pause
+		from = from.Add(pause)
+	}
+	g.used += c.active(g.control, from, now)
+	g.since = now
+}
+
+// paused returns true if the clock is stopped for the vacation of the
+// player whose turn it is.
+func (c *Correspondence) paused(g *correspondenceGame) bool {
+	return c.vacations[g.player.ID] && g.vacationLeft() > 0
+}
+
+// deadline is when a settled clock runs out, with the player staying on
+// vacation for as long as they are able.
+func (c *Correspondence) deadline(g *correspondenceGame, now time.Time) time.Time {
+	if c.paused(g) {
+		now = now.Add(g.vacationLeft())
+	}
+	return c.addActive(g.control, now, g.remaining())
+}
+
+// active is how much of the time from from to to a clock runs for, which
+// is all of it unless it stops for weekends.
+func (c *Correspondence) active(control DayControl, from, to time.Time) time.Duration {
+	if !control.WeekendPause {
+		return to.Sub(from)
+	}
+
+	var out time.Duration
+	for from.Before(to) {
+		next := c.nextDay(from)
+		if next.After(to) {
+			next = to
+		}
+		if !c.weekend(from) {
+			out += next.Sub(from)
+		}
+		from = next
+	}
+	return out
+}
+
+// addActive is when a clock that starts at from has run for d.
+func (c *Correspondence) addActive(control DayControl, from time.Time, d time.Duration) time.Time {
+	if !control.WeekendPause {
+		return from.Add(d)
+	}
+
+	for {
+		next := c.nextDay(from)
+		if !c.weekend(from) {
+			if d <= next.Sub(from) {
+				return from.Add(d)
+			}
+			d -= next.Sub(from)
+		}
+		from = next
+	}
+}
+
+// nextDay is midnight at the end of the day t is in.
+func (c *Correspondence) nextDay(t time.Time) time.Time {
+	t = t.In(c.location)
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.location)
+}
+
+// weekend returns true if t is on a Saturday or Sunday.
+func (c *Correspondence) weekend(t time.Time) bool {
+	switch t.In(c.location).Weekday() {
+	case time.Saturday, time.Sunday:
+		return true
+	}
+	return false
+}
+
+// remaining is how much time the player to move has left, as of the
+// last time the clock was settled.
+func (g *correspondenceGame) remaining() time.Duration {
+	return time.Duration(g.control.DaysPerMove)*day - g.used
+}
+
+// vacationLeft is how much vacation the player to move has left in the
+// game.
+func (g *correspondenceGame) vacationLeft() time.Duration {
+	return time.Duration(g.control.VacationDays)*day - g.vacation[g.colour]
+}
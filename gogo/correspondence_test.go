@@ -0,0 +1,388 @@
+package gogo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// monday is the Monday that correspondence tests start on.
+var monday = time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+
+// testCorrespondence returns a Correspondence with a clock that only
+// moves when told to, and the notifications it sends.
+func testCorrespondence(t *testing.T, start time.Time, opts ...CorrespondenceOption) (*Correspondence, *MemoryStore, func(time.Duration), *[]Notification) {
+	sent := &[]Notification{}
+	opts = append(opts, WithNotifier(NotifierFunc(func(n Notification) {
+		*sent = append(*sent, n)
+	})))
+	store := NewMemoryStore()
+	c, err := NewCorrespondence(store, opts...)
+	require.NoError(t, err)
+
+	now := start
+	c.now = func() time.Time { return now }
+	return c, store, func(d time.Duration) { now = now.Add(d) }, sent
+}
+
+// addCorrespondenceGame adds a game between alice, as black, and bob.
+func addCorrespondenceGame(t *testing.T, c *Correspondence, control DayControl) string {
+	b, err := NewBoard(9, WithSeat(blackPlayer, alice), WithSeat(whitePlayer, bob))
+	require.NoError(t, err)
+	require.NoError(t, c.Add(&b, control))
+	return b.Code()
+}
+
+func TestCorrespondenceDeadline(t *testing.T) {
+	friday := monday.Add(-3 * day)
+	tests := []struct {
+		control  DayControl
+		start    time.Time
+		zone     *time.Location
+		expected time.Time
+	}{
+		{
+			control:  DayControl{DaysPerMove: 3},
+			start:    friday.Add(12 * time.Hour),
+			expected: monday.Add(12 * time.Hour),
+		},
+		{
+			control:  DayControl{DaysPerMove: 3, WeekendPause: true},
+			start:    friday.Add(12 * time.Hour),
+			expected: monday.Add(2*day + 12*time.Hour),
+		},
+		{
+			// the clock doesn't start until the weekend is over
+			control:  DayControl{DaysPerMove: 1, WeekendPause: true},
+			start:    friday.Add(day + 10*time.Hour),
+			expected: monday.Add(day),
+		},
+		{
+			// weekends are where the time zone says they are
+			control:  DayControl{DaysPerMove: 1, WeekendPause: true},
+			start:    friday.Add(day + 6*time.Hour),
+			zone:     time.FixedZone("UTC-10", -10*60*60),
+			expected: monday.Add(day + 6*time.Hour),
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v", i, tt.control), func(t *testing.T) {
+			var opts []CorrespondenceOption
+			if tt.zone != nil {
+				opts = append(opts, WithTimeZone(tt.zone))
+			}
+			c, _, _, sent := testCorrespondence(t, tt.start, opts...)
+			code := addCorrespondenceGame(t, c, tt.control)
+
+			clock, err := c.Clock(code)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(clock.Deadline), "deadline %v", clock.Deadline)
+			assert.Equal(t, time.Duration(tt.control.DaysPerMove)*day, clock.Remaining)
+			require.Len(t, *sent, 1)
+			assert.True(t, tt.expected.Equal((*sent)[0].Deadline))
+		})
+	}
+}
+
+func TestCorrespondencePlay(t *testing.T) {
+	c, store, advance, sent := testCorrespondence(t, monday)
+	code := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 3})
+	assert.Equal(t, []string{code}, c.Games())
+	assert.Equal(t, []Notification{
+		{Type: NotifyTurn, Game: code, Player: alice, Colour: blackPlayer, Deadline: monday.Add(3 * day)},
+	}, *sent)
+
+	advance(day)
+	require.NoError(t, c.Check())
+	assert.Len(t, *sent, 1)
+
+	_, err := c.Play(code, bob.ID, "C3")
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+	_, err = c.Play("nope", alice.ID, "C3")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+	_, err = c.Play(code, alice.ID, "E5")
+	require.NoError(t, err)
+
+	clock, err := c.Clock(code)
+	require.NoError(t, err)
+	assert.Equal(t, bob, clock.Player)
+	assert.Equal(t, 3*day, clock.Remaining)
+	require.Len(t, *sent, 2)
+	assert.Equal(t, Notification{Type: NotifyTurn, Game: code, Player: bob, Colour: whitePlayer, Deadline: monday.Add(4 * day)}, (*sent)[1])
+
+	// bob is warned when less than a day is left
+	advance(2*day + time.Hour)
+	require.NoError(t, c.Check())
+	require.NoError(t, c.Check())
+	require.Len(t, *sent, 3)
+	assert.Equal(t, NotifyLowTime, (*sent)[2].Type)
+	assert.Equal(t, bob, (*sent)[2].Player)
+
+	advance(day)
+	require.NoError(t, c.Check())
+	assert.Empty(t, c.Games())
+	_, err = c.Clock(code)
+	assert.ErrorIs(t, err, ErrGameNotFound)
+
+	loaded, err := store.Load(code)
+	require.NoError(t, err)
+	res, ok := loaded.GameResult()
+	require.True(t, ok)
+	assert.Equal(t, "B+T", res.String())
+}
+
+func TestCorrespondenceLateMove(t *testing.T) {
+	c, store, advance, _ := testCorrespondence(t, monday)
+	code := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 1})
+
+	// running out of time loses even if Check hasn't noticed yet
+	advance(day)
+	_, err := c.Play(code, alice.ID, "E5")
+	assert.ErrorIs(t, err, ErrGameOver)
+
+	loaded, err := store.Load(code)
+	require.NoError(t, err)
+	assert.Equal(t, 0, loaded.MoveNumber())
+	res, ok := loaded.GameResult()
+	require.True(t, ok)
+	assert.Equal(t, "W+T", res.String())
+}
+
+func TestCorrespondenceGameOver(t *testing.T) {
+	c, store, _, sent := testCorrespondence(t, monday)
+	code := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 1})
+
+	_, err := c.Pass(code, alice.ID)
+	require.NoError(t, err)
+	_, err = c.Pass(code, bob.ID)
+	require.NoError(t, err)
+	assert.Empty(t, c.Games())
+	assert.Len(t, *sent, 2, "nobody is told to play once the game is over")
+
+	code = addCorrespondenceGame(t, c, DayControl{DaysPerMove: 1})
+	require.NoError(t, c.Resign(code, bob.ID))
+	assert.Empty(t, c.Games())
+	loaded, err := store.Load(code)
+	require.NoError(t, err)
+	assert.True(t, loaded.GameOver())
+}
+
+func TestCorrespondenceAddErrors(t *testing.T) {
+	c, store, _, _ := testCorrespondence(t, monday)
+
+	seated, err := NewBoard(9, WithSeat(blackPlayer, alice), WithSeat(whitePlayer, bob))
+	require.NoError(t, err)
+	assert.Error(t, c.Add(&seated, DayControl{}))
+	assert.Error(t, c.Add(&seated, DayControl{DaysPerMove: 1, VacationDays: -1}))
+	require.NoError(t, c.Add(&seated, DayControl{DaysPerMove: 1}))
+	assert.Error(t, c.Add(&seated, DayControl{DaysPerMove: 1}), "already added")
+
+	unseated, err := NewBoard(9, WithSeat(blackPlayer, alice))
+	require.NoError(t, err)
+	assert.Error(t, c.Add(&unseated, DayControl{DaysPerMove: 1}))
+
+	over, err := NewBoard(9, WithSeat(blackPlayer, alice), WithSeat(whitePlayer, bob))
+	require.NoError(t, err)
+	require.NoError(t, over.Resign(blackPlayer))
+	assert.ErrorIs(t, c.Add(&over, DayControl{DaysPerMove: 1}), ErrGameOver)
+
+	// a game isn't added over another one saved with the same code
+	saved, err := NewBoard(9, WithSeat(blackPlayer, alice), WithSeat(whitePlayer, bob))
+	require.NoError(t, err)
+	require.NoError(t, store.Save(&saved))
+	clash, err := NewBoard(9, WithSeat(blackPlayer, carol), WithSeat(whitePlayer, bob))
+	require.NoError(t, err)
+	clash.code = saved.Code()
+	assert.ErrorIs(t, c.Add(&clash, DayControl{DaysPerMove: 1}), ErrCodeTaken)
+	loaded, err := store.Load(saved.Code())
+	require.NoError(t, err)
+	seat, _ := loaded.Seat(blackPlayer)
+	assert.Equal(t, alice, seat)
+
+	_, err = NewCorrespondence(nil)
+	assert.Error(t, err)
+	_, err = NewCorrespondence(NewMemoryStore(), WithLowTime(0))
+	assert.Error(t, err)
+}
+
+func TestCorrespondenceVacation(t *testing.T) {
+	c, _, advance, _ := testCorrespondence(t, monday)
+	code := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 1, VacationDays: 2})
+
+	advance(12 * time.Hour)
+	require.NoError(t, c.StartVacation(alice.ID))
+	assert.Error(t, c.StartVacation(alice.ID))
+	assert.True(t, c.OnVacation(alice.ID))
+
+	advance(day)
+	clock, err := c.Clock(code)
+	require.NoError(t, err)
+	assert.True(t, clock.OnVacation)
+	assert.Equal(t, 12*time.Hour, clock.Remaining)
+	assert.Equal(t, day, clock.Vacation)
+	assert.Equal(t, monday.Add(3*day), clock.Deadline)
+
+	// the clock starts again once the vacation is used up
+	advance(day + 6*time.Hour)
+	require.NoError(t, c.Check())
+	clock, err = c.Clock(code)
+	require.NoError(t, err)
+	assert.False(t, clock.OnVacation)
+	assert.Equal(t, 6*time.Hour, clock.Remaining)
+	assert.Equal(t, time.Duration(0), clock.Vacation)
+
+	require.NoError(t, c.EndVacation(alice.ID))
+	assert.Error(t, c.EndVacation(alice.ID))
+	assert.False(t, c.OnVacation(alice.ID))
+}
+
+func TestCorrespondenceVacationTurns(t *testing.T) {
+	c, _, advance, _ := testCorrespondence(t, monday)
+	code := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 1, VacationDays: 1})
+
+	// bob's vacation isn't used while it's alice's turn
+	require.NoError(t, c.StartVacation(bob.ID))
+	advance(12 * time.Hour)
+	_, err := c.Play(code, alice.ID, "E5")
+	require.NoError(t, err)
+
+	advance(18 * time.Hour)
+	clock, err := c.Clock(code)
+	require.NoError(t, err)
+	assert.Equal(t, bob, clock.Player)
+	assert.True(t, clock.OnVacation)
+	assert.Equal(t, day, clock.Remaining)
+	assert.Equal(t, 6*time.Hour, clock.Vacation)
+
+	require.NoError(t, c.EndVacation(bob.ID))
+	advance(6 * time.Hour)
+	clock, err = c.Clock(code)
+	require.NoError(t, err)
+	assert.False(t, clock.OnVacation)
+	assert.Equal(t, 18*time.Hour, clock.Remaining)
+	assert.Equal(t, 6*time.Hour, clock.Vacation, "vacation isn't used once it's over")
+}
+
+func TestCorrespondenceRestart(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(_ *testing.T) Store { return NewMemoryStore() },
+		"json file": func(t *testing.T) Store {
+			s, err := NewFileStore(t.TempDir(), FormatJSON)
+			require.NoError(t, err)
+			return s
+		},
+	}
+
+	for name, x := range stores {
+		newStore := x
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			now := monday
+			var sent []Notification
+			open := func() *Correspondence {
+				c, err := NewCorrespondence(store, WithNotifier(NotifierFunc(func(n Notification) {
+					sent = append(sent, n)
+				})))
+				require.NoError(t, err)
+				c.now = func() time.Time { return now }
+				return c
+			}
+
+			c := open()
+			code := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 3, VacationDays: 2})
+			low := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 3})
+			over := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 1})
+			require.NoError(t, c.Resign(over, bob.ID))
+
+			now = now.Add(12 * time.Hour)
+			_, err := c.Play(code, alice.ID, "E5")
+			require.NoError(t, err)
+			now = now.Add(day)
+			require.NoError(t, c.StartVacation(bob.ID))
+			now = now.Add(12 * time.Hour)
+			sent = nil
+			require.NoError(t, c.Check())
+			require.Len(t, sent, 1)
+			assert.Equal(t, Notification{Type: NotifyLowTime, Game: low, Player: alice, Colour: blackPlayer, Deadline: monday.Add(3 * day)}, sent[0])
+			before, err := c.Clock(code)
+			require.NoError(t, err)
+			sent = nil
+
+			// a new Correspondence on the same store carries on the clocks
+			again := open()
+			assert.ElementsMatch(t, []string{code, low}, again.Games())
+			assert.True(t, again.OnVacation(bob.ID))
+			after, err := again.Clock(code)
+			require.NoError(t, err)
+			assert.Equal(t, before.Remaining, after.Remaining)
+			assert.Equal(t, before.Vacation, after.Vacation)
+			assert.True(t, before.Deadline.Equal(after.Deadline))
+			assert.Equal(t, bob, after.Player)
+
+			// and doesn't warn about low time again
+			require.NoError(t, again.Check())
+			assert.Empty(t, sent)
+		})
+	}
+}
+
+func TestCorrespondenceConditionalMoves(t *testing.T) {
+	c, store, advance, sent := testCorrespondence(t, monday)
+	code := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 3})
+
+	assert.ErrorIs(t, c.AddConditionalMoves("nope", bob.ID, "E5", "C3"), ErrGameNotFound)
+	assert.Error(t, c.AddConditionalMoves(code, alice.ID, "E5", "C3"), "it's alice's turn")
+	require.NoError(t, c.AddConditionalMoves(code, bob.ID, "E5", "C3"))
+	loaded, err := store.Load(code)
+	require.NoError(t, err)
+	assert.Len(t, loaded.ConditionalMoves(whitePlayer), 1)
+
+	// bob's answer is played straight away, and alice's clock restarts
+	advance(day)
+	_, err = c.Play(code, alice.ID, "E5")
+	require.NoError(t, err)
+	loaded, err = store.Load(code)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.MoveNumber())
+
+	clock, err := c.Clock(code)
+	require.NoError(t, err)
+	assert.Equal(t, alice, clock.Player)
+	assert.Equal(t, 3*day, clock.Remaining)
+	require.Len(t, *sent, 2)
+	assert.Equal(t, Notification{Type: NotifyTurn, Game: code, Player: alice, Colour: blackPlayer, Deadline: monday.Add(4 * day)}, (*sent)[1])
+
+	// nothing can be queued once the player to move runs out of time
+	advance(3 * day)
+	assert.ErrorIs(t, c.AddConditionalMoves(code, bob.ID, "D4", "C4"), ErrGameOver)
+}
+
+func TestCorrespondenceRestartSkipsBadGames(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, FormatJSON)
+	require.NoError(t, err)
+	c, err := NewCorrespondence(store)
+	require.NoError(t, err)
+	code := addCorrespondenceGame(t, c, DayControl{DaysPerMove: 3})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "BAD1.json"), []byte("{not json"), 0o644))
+
+	// the bad game is reported, and the good one still picked up
+	var logged [][]interface{}
+	again, err := NewCorrespondence(store, WithCorrespondenceLogger(LoggerFunc(func(level LogLevel, msg string, args ...interface{}) {
+		assert.Equal(t, LevelWarn, level)
+		logged = append(logged, append([]interface{}{msg}, args[:2]...))
+	})))
+	require.NoError(t, err)
+	assert.Equal(t, []string{code}, again.Games())
+	assert.Equal(t, [][]interface{}{{logRestore, "game", "BAD1"}}, logged)
+
+	_, err = NewCorrespondence(store, WithCorrespondenceLogger(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package gogo
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrOccupied is a stone being placed on a point that already has
+	// one.
+	ErrOccupied = errors.New("point is already occupied")
+	// ErrOffBoard is a point outside the board.
+	ErrOffBoard = errors.New("point is off the board")
+	// ErrKo is a move the ko rule forbids, whether it's retaking a ko
+	// straight away or, under superko, repeating an earlier position.
+	ErrKo = errors.New("move breaks the ko rule")
+	// ErrSuicide is a move that would leave its own string without
+	// liberties, under rules that forbid it.
+	ErrSuicide = errors.New("move is suicide")
+	// ErrNotYourTurn is a move made by a player whose turn it isn't.
+	ErrNotYourTurn = errors.New("not their turn")
+	// ErrGameOver is a move made after the game has ended.
+	ErrGameOver = errors.New("the game is over")
+	// ErrBadCoordinate is a point that can't be parsed.
+	ErrBadCoordinate = errors.New("bad coordinate")
+	// ErrBoardSize is a board that's too big or too small.
+	ErrBoardSize = errors.New("invalid board size")
+)
+
+// MoveError is a stone that can't be placed. It wraps the reason, which
+// is one of the Err values above unless the Rules say otherwise, so it
+// can be checked with errors.Is:
+//
+//	var moveErr *MoveError
+//	if errors.As(err, &moveErr) && errors.Is(err, ErrOccupied) {
+//		// moveErr.Point is occupied
+//	}
+type MoveError struct {
+	// Player is the player who tried to move.
+	Player string
+	// Name is the point as it was given, like "C4", or as the board names
+	// it.
+	Name string
+	// Point is the point, if Name could be parsed.
+	Point Point
+	// Err is why the stone can't be placed there.
+	Err error
+}
+
+// Error ...
+func (e *MoveError) Error() string {
+	return fmt.Sprintf("can't place at %q, %v", e.Name, e.Err)
+}
+
+// Unwrap ...
+func (e *MoveError) Unwrap() error {
+	return e.Err
+}
+
+// moveError returns a MoveError for the current player placing at p.
+// Errors that are already a MoveError are returned as they are.
+func (b Board) moveError(p Point, err error) error {
+	var moveErr *MoveError
+	if errors.As(err, &moveErr) {
+		return err
+	}
+	return &MoveError{Player: b.currentPlayer, Name: b.pointName(p), Point: p, Err: err}
+}
@@ -0,0 +1,119 @@
+package gogo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveErrors(t *testing.T) {
+	tests := []struct {
+		opts        []Option
+		actions     []string
+		move        string
+		expectErr   error
+		expectPoint *Point
+	}{
+		{actions: []string{"B2"}, move: "B2", expectErr: ErrOccupied, expectPoint: &Point{X: 1, Y: 1}},
+		{move: "E1", expectErr: ErrOffBoard, expectPoint: &Point{X: 4, Y: 0}},
+		{move: "A5", expectErr: ErrOffBoard, expectPoint: &Point{X: 0, Y: 4}},
+		{move: "A0", expectErr: ErrBadCoordinate},
+		{move: "!1", expectErr: ErrBadCoordinate},
+		{move: "A", expectErr: ErrBadCoordinate},
+		{
+			actions:     []string{"A2", "C1", "B3", "C3", "B1", "D2", "pass", "B2", "C2"},
+			move:        "B2",
+			expectErr:   ErrKo,
+			expectPoint: &Point{X: 1, Y: 1},
+		},
+		{
+			opts:        []Option{WithRules(Chinese)},
+			actions:     []string{"A2", "C1", "B3", "C3", "B1", "D2", "pass", "B2", "C2"},
+			move:        "B2",
+			expectErr:   ErrKo,
+			expectPoint: &Point{X: 1, Y: 1},
+		},
+		{
+			opts:        []Option{WithRules(Japanese)},
+			actions:     []string{"B1", "pass", "A2"},
+			move:        "A1",
+			expectErr:   ErrSuicide,
+			expectPoint: &Point{X: 0, Y: 0},
+		},
+		{
+			actions:     []string{"resign:white"},
+			move:        "A1",
+			expectErr:   ErrGameOver,
+			expectPoint: &Point{X: 0, Y: 0},
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v move %v", i, strings.Join(tt.actions, "_"), tt.move), func(t *testing.T) {
+			board, err := NewBoard(4, tt.opts...)
+			require.NoError(t, err)
+			require.NoError(t, play(&board, tt.actions))
+			player := board.CurrentPlayer()
+
+			_, err = board.Play(tt.move)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.expectErr)
+
+			var moveErr *MoveError
+			require.ErrorAs(t, err, &moveErr)
+			assert.Equal(t, player, moveErr.Player)
+			assert.Equal(t, tt.move, moveErr.Name)
+			if tt.expectPoint != nil {
+				assert.Equal(t, *tt.expectPoint, moveErr.Point)
+			}
+		})
+	}
+}
+
+func TestOtherErrors(t *testing.T) {
+	_, err := NewBoard(MinBoardSize - 1)
+	assert.ErrorIs(t, err, ErrBoardSize)
+	assert.Equal(t, "invalid board size, 3 is smaller than the minimum of 4", err.Error())
+	_, err = NewRectBoard(9, MaxBoardSize+1)
+	assert.ErrorIs(t, err, ErrBoardSize)
+
+	board, err := NewBoard(4)
+	require.NoError(t, err)
+	require.NoError(t, play(&board, []string{"A1", "pass", "pass"}))
+	_, err = board.Pass()
+	assert.ErrorIs(t, err, ErrGameOver)
+	assert.ErrorIs(t, board.Resign(blackPlayer), ErrGameOver)
+
+	_, err = Rebuild(4, []Event{{Type: EventPlace, Player: whitePlayer, Position: "A1"}})
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+
+	_, err = DecodeSGF(strings.NewReader("(;SZ[9];W[aa])"))
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+
+	for _, s := range []string{"", "I9", "A-1"} {
+		_, err := ParseGTP(s)
+		assert.ErrorIs(t, err, ErrBadCoordinate, s)
+	}
+	_, err = ParseSGF("zz", 9)
+	assert.ErrorIs(t, err, ErrBadCoordinate)
+	_, err = ParseXY("1")
+	assert.ErrorIs(t, err, ErrBadCoordinate)
+
+	graph, err := NewGraphBoard(hexFlower)
+	require.NoError(t, err)
+	_, err = graph.Play("nowhere")
+	assert.ErrorIs(t, err, ErrBadCoordinate)
+
+	// errors from other rules are wrapped too
+	tengen, err := NewBoard(9, WithRules(noTengen{DefaultRuleset}))
+	require.NoError(t, err)
+	_, err = tengen.Play("E5")
+	var moveErr *MoveError
+	require.True(t, errors.As(err, &moveErr))
+	assert.Equal(t, Point{X: 4, Y: 4}, moveErr.Point)
+}
@@ -0,0 +1,267 @@
+package gogo
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType is the kind of action an Event records.
+type EventType string
+
+const (
+	// EventPlace is a stone being placed at Position.
+	EventPlace EventType = "place"
+	// EventPass is Player passing their turn.
+	EventPass EventType = "pass"
+	// EventResign is Player resigning the game.
+	EventResign EventType = "resign"
+	// EventUndo takes back the last place or pass.
+	EventUndo EventType = "undo"
+	// EventToggleDead marks the string at Position as dead, or alive
+	// again, once both players have passed.
+	EventToggleDead EventType = "toggle_dead"
+	// EventTimeout is Player running out of time.
+	EventTimeout EventType = "timeout"
+	// EventForfeit is Player forfeiting the game, like not turning up.
+	EventForfeit EventType = "forfeit"
+)
+
+// Event is a single action taken in a game. Every action is appended to
+// the game's event log, and replaying the log on an empty board rebuilds
+// the game exactly.
+type Event struct {
+	Type     EventType `json:"type"`
+	Player   string    `json:"player,omitempty"`
+	Position string    `json:"position,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Events returns a copy of every action taken so far, in order.
+func (b Board) Events() []Event {
+	out := make([]Event, len(b.events))
+	copy(out, b.events)
+	return out
+}
+
+// appendEvent ...
+func (b *Board) appendEvent(t EventType, player, position string) {
+	b.events = append(b.events, Event{Type: t, Player: player, Position: position, Time: b.now()})
+}
+
+// GameOver returns true once every player has passed in a row, a player
+// has resigned, run out of time or forfeited, or the rules say someone
+// has won. GameResult says how it ended.
+func (b Board) GameOver() bool {
+	return b.endReason != ""
+}
+
+// Pass ends the current player's turn without placing a stone. The game
+// ends when every player passes in a row.
+func (b *Board) Pass() (Result, error) {
+	if b.GameOver() {
+		return Result{}, fmt.Errorf("can't pass, %w", ErrGameOver)
+	}
+
+	b.appendEvent(EventPass, b.currentPlayer, "")
+	res := b.play(passMove)
+	b.answer(nil)
+	return res, nil
+}
+
+// pass ...
+func (b *Board) pass() Result {
+	b.ko = -1
+	b.moves = append(b.moves, move{idx: passMove, piece: b.nextPiece})
+	b.log(LevelInfo, logPass, "player", b.currentPlayer, "move", b.MoveNumber())
+
+	// with pass stones, every pass hands the next player a prisoner, and
+	// white has to be the one to pass last
+	passStones := b.Ruleset().PassStones
+	if passStones {
+		b.prisoners[b.nextColour(b.nextPiece)]++
+	}
+	if b.passesInARow() >= len(b.players()) && (!passStones || b.nextPiece == whitePiece) {
+		b.endReason = EventPass
+	}
+
+	return b.advanceToNextTurn()
+}
+
+// Resign ends the game with player ( "black", "white", ... ) losing.
+func (b *Board) Resign(player string) error {
+	return b.forfeit(EventResign, player)
+}
+
+// Timeout ends the game with player ( "black", "white", ... ) losing
+// because they ran out of time.
+func (b *Board) Timeout(player string) error {
+	return b.forfeit(EventTimeout, player)
+}
+
+// Forfeit ends the game with player ( "black", "white", ... ) losing
+// without playing it out, like when they don't turn up.
+func (b *Board) Forfeit(player string) error {
+	return b.forfeit(EventForfeit, player)
+}
+
+// forfeit ...
+func (b *Board) forfeit(reason EventType, player string) error {
+	if !b.isPlaying(player) {
+		return fmt.Errorf("unknown player %q", player)
+	}
+	if b.GameOver() {
+		return fmt.Errorf("can't %v, %w", reason, ErrGameOver)
+	}
+
+	b.appendEvent(reason, player, "")
+	b.endReason = reason
+	b.loser = player
+	b.logGameOver()
+	return nil
+}
+
+// Undo takes back the last stone placed or pass, including a pass that
+// ended the game. Games that ended by resignation, timeout or forfeit
+// can't be undone. Every player's conditional moves are cancelled, as
+// they were queued for the position that's been taken back.
+func (b *Board) Undo() error {
+	if _, ok := forfeitReasons[b.endReason]; ok {
+		return fmt.Errorf("can't undo, %w", ErrGameOver)
+	}
+	if len(b.moves) == 0 {
+		return fmt.Errorf("can't undo, no moves have been played")
+	}
+
+	last := b.moves[len(b.moves)-1]
+	prev := b.replay(len(b.moves) - 1)
+	prev.events = b.events
+	cancelled := b.conditional
+	*b = prev
+
+	b.appendEvent(EventUndo, colourToPlayer(last.piece), "")
+	for _, colour := range b.players() {
+		if tree, ok := cancelled[colour]; ok {
+			b.log(LevelInfo, logConditional, "player", colourToPlayer(colour), "cancelled", len(tree))
+		}
+	}
+	return nil
+}
+
+// ToggleDead marks the string at input as dead, or alive again if it was
+// already marked. Dead stones count as captured when scoring. It's only
+// allowed once the game has ended with both players passing.
+func (b *Board) ToggleDead(input string) error {
+	if b.endReason != EventPass {
+		return fmt.Errorf("can't mark dead stones until both players have passed")
+	}
+
+	idx, err := b.inputToIdx(input)
+	if err != nil {
+		return fmt.Errorf("can't mark %q as dead, %w", input, err)
+	}
+	piece := b.board[idx]
+	if piece == emptySpace {
+		return fmt.Errorf("can't mark %q as dead, there's no stone there", input)
+	}
+
+	dead := !b.dead[idx]
+	for _, i := range b.stringAt(idx) {
+		if dead {
+			b.dead[i] = true
+		} else {
+			delete(b.dead, i)
+		}
+	}
+
+	b.appendEvent(EventToggleDead, colourToPlayer(piece), input)
+	return nil
+}
+
+// At rebuilds the game as it was after the given number of moves, by
+// replaying the event log. Dead stone markings, resignations, timeouts
+// and forfeits that happened at that point are included.
+func (b Board) At(moveNumber int) (Board, error) {
+	return rebuild(b.replay(0), b.events, moveNumber)
+}
+
+// Rebuild replays an event log on an empty board of the given size,
+// created with opts.
+func Rebuild(size int, events []Event, opts ...Option) (Board, error) {
+	return RebuildRect(size, size, events, opts...)
+}
+
+// RebuildRect replays an event log on an empty rectangular board.
+func RebuildRect(width, height int, events []Event, opts ...Option) (Board, error) {
+	b, err := NewRectBoard(width, height, opts...)
+	if err != nil {
+		return Board{}, err
+	}
+	return rebuild(b, events, -1)
+}
+
+// rebuild replays events on b until the next one would take the game
+// past upTo moves. A negative upTo replays everything. Nothing is logged
+// while the events are replayed.
+func rebuild(b Board, events []Event, upTo int) (Board, error) {
+	logger := b.logger
+	b.logger = nil
+	for i, e := range events {
+		if upTo >= 0 && (e.Type == EventPlace || e.Type == EventPass) && b.MoveNumber() >= upTo {
+			break
+		}
+
+		if err := b.apply(e); err != nil {
+			return Board{}, fmt.Errorf("unable to replay event %d: %w", i+1, err)
+		}
+		b.events[len(b.events)-1].Time = e.Time
+	}
+
+	b.logger = logger
+	return b, nil
+}
+
+// apply performs the action recorded in e.
+func (b *Board) apply(e Event) error {
+	isTurn := func() error {
+		if e.Player != b.currentPlayer {
+			return fmt.Errorf("%v by %q, %w, it's %v's turn", e.Type, e.Player, ErrNotYourTurn, b.currentPlayer)
+		}
+		return nil
+	}
+
+	switch e.Type {
+	case EventPlace:
+		if err := isTurn(); err != nil {
+			return err
+		}
+		_, err := b.Play(e.Position)
+		return err
+	case EventPass:
+		if err := isTurn(); err != nil {
+			return err
+		}
+		_, err := b.Pass()
+		return err
+	case EventResign:
+		return b.Resign(e.Player)
+	case EventTimeout:
+		return b.Timeout(e.Player)
+	case EventForfeit:
+		return b.Forfeit(e.Player)
+	case EventUndo:
+		return b.Undo()
+	case EventToggleDead:
+		return b.ToggleDead(e.Position)
+	}
+
+	return fmt.Errorf("unknown event type %q", e.Type)
+}
+
+// passesInARow counts the passes at the end of the move history.
+func (b Board) passesInARow() int {
+	n := 0
+	for i := len(b.moves) - 1; i >= 0 && b.moves[i].idx == passMove; i-- {
+		n++
+	}
+	return n
+}
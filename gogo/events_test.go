@@ -0,0 +1,289 @@
+package gogo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// play runs a list of actions against a board, where "pass", "undo",
+// "resign:<player>", "timeout:<player>", "forfeit:<player>" and
+// "dead:<pos>" are actions and anything else is placed.
+func play(b *Board, actions []string) error {
+	for _, a := range actions {
+		var err error
+		switch {
+		case a == "pass":
+			_, err = b.Pass()
+		case a == "undo":
+			err = b.Undo()
+		case strings.HasPrefix(a, "resign:"):
+			err = b.Resign(strings.TrimPrefix(a, "resign:"))
+		case strings.HasPrefix(a, "timeout:"):
+			err = b.Timeout(strings.TrimPrefix(a, "timeout:"))
+		case strings.HasPrefix(a, "forfeit:"):
+			err = b.Forfeit(strings.TrimPrefix(a, "forfeit:"))
+		case strings.HasPrefix(a, "dead:"):
+			err = b.ToggleDead(strings.TrimPrefix(a, "dead:"))
+		default:
+			_, err = b.Play(a)
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %w", a, err)
+		}
+	}
+	return nil
+}
+
+func TestGameActionsAreRecorded(t *testing.T) {
+	tests := []struct {
+		actions      []string
+		valid        bool
+		expectEvents []EventType
+		expectBoard  string
+		expectPlayer string
+		expectOver   bool
+	}{
+		{
+			actions:      []string{"A1", "pass", "B1"},
+			valid:        true,
+			expectEvents: []EventType{EventPlace, EventPass, EventPlace},
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 B B X X
+  A B C D`,
+			expectPlayer: whitePlayer,
+		},
+		{
+			actions:      []string{"A1", "pass", "pass"},
+			valid:        true,
+			expectEvents: []EventType{EventPlace, EventPass, EventPass},
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 B X X X
+  A B C D`,
+			expectPlayer: whitePlayer,
+			expectOver:   true,
+		},
+		{
+			actions:      []string{"A1", "B1", "undo"},
+			valid:        true,
+			expectEvents: []EventType{EventPlace, EventPlace, EventUndo},
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 B X X X
+  A B C D`,
+			expectPlayer: whitePlayer,
+		},
+		// undoing a capture puts the captured stone back
+		{
+			actions: []string{"B1", "B2", "A2", "A3", "C2", "C3", "B3", "undo"},
+			valid:   true,
+			expectEvents: []EventType{
+				EventPlace, EventPlace, EventPlace, EventPlace, EventPlace, EventPlace, EventPlace, EventUndo,
+			},
+			expectBoard: `4 X X X X
+3 W X W X
+2 B W B X
+1 X B X X
+  A B C D`,
+			expectPlayer: blackPlayer,
+		},
+		// undoing the second pass re-opens the game
+		{
+			actions:      []string{"pass", "pass", "undo", "A1"},
+			valid:        true,
+			expectEvents: []EventType{EventPass, EventPass, EventUndo, EventPlace},
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 W X X X
+  A B C D`,
+			expectPlayer: blackPlayer,
+		},
+		{
+			actions:      []string{"A1", "resign:white"},
+			valid:        true,
+			expectEvents: []EventType{EventPlace, EventResign},
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 B X X X
+  A B C D`,
+			expectPlayer: whitePlayer,
+			expectOver:   true,
+		},
+		{
+			actions:      []string{"timeout:black"},
+			valid:        true,
+			expectEvents: []EventType{EventTimeout},
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 X X X X
+  A B C D`,
+			expectPlayer: blackPlayer,
+			expectOver:   true,
+		},
+		{
+			actions:      []string{"A1", "pass", "pass", "dead:A1"},
+			valid:        true,
+			expectEvents: []EventType{EventPlace, EventPass, EventPass, EventToggleDead},
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 B X X X
+  A B C D`,
+			expectPlayer: whitePlayer,
+			expectOver:   true,
+		},
+		{actions: []string{"undo"}},
+		{actions: []string{"resign:red"}},
+		{actions: []string{"resign:black", "A1"}},
+		{actions: []string{"resign:black", "undo"}},
+		{actions: []string{"resign:black", "resign:white"}},
+		{actions: []string{"timeout:white", "pass"}},
+		{actions: []string{"pass", "pass", "A1"}},
+		{actions: []string{"A1", "dead:A1"}},
+		{actions: []string{"pass", "pass", "dead:A1"}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewBoard(4)
+			require.NoError(t, err)
+
+			err = play(&board, tt.actions)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			gotEvents := []EventType{}
+			for _, e := range board.Events() {
+				gotEvents = append(gotEvents, e.Type)
+				assert.False(t, e.Time.IsZero())
+			}
+			assert.Equal(t, tt.expectEvents, gotEvents)
+			assert.Equal(t, tt.expectBoard, board.String())
+			assert.Equal(t, tt.expectPlayer, board.CurrentPlayer())
+			assert.Equal(t, tt.expectOver, board.GameOver())
+
+			rebuilt, err := Rebuild(4, board.Events())
+			require.NoError(t, err)
+			assert.Equal(t, board.Events(), rebuilt.Events())
+			assert.Equal(t, board.String(), rebuilt.String())
+			assert.Equal(t, board.GameOver(), rebuilt.GameOver())
+		})
+	}
+}
+
+func TestRebuildGameAtMove(t *testing.T) {
+	board, err := NewBoard(4)
+	require.NoError(t, err)
+	require.NoError(t, play(&board, []string{"A1", "B1", "C1", "undo", "D1", "pass", "pass", "dead:D1"}))
+	code := board.Code()
+
+	tests := []struct {
+		move         int
+		expectBoard  string
+		expectPlayer string
+		expectOver   bool
+		expectEvents int
+	}{
+		{
+			move: 0,
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 X X X X
+  A B C D`,
+			expectPlayer: blackPlayer,
+		},
+		{
+			move: 2,
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 B W X X
+  A B C D`,
+			expectPlayer: blackPlayer,
+			expectEvents: 2,
+		},
+		// the undo is replayed, and the stone that replaced C1 is shown
+		{
+			move: 3,
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 B W X B
+  A B C D`,
+			expectPlayer: whitePlayer,
+			expectEvents: 5,
+		},
+		{
+			move: 5,
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 B W X B
+  A B C D`,
+			expectPlayer: whitePlayer,
+			expectOver:   true,
+			expectEvents: 8,
+		},
+		{
+			move: 100,
+			expectBoard: `4 X X X X
+3 X X X X
+2 X X X X
+1 B W X B
+  A B C D`,
+			expectPlayer: whitePlayer,
+			expectOver:   true,
+			expectEvents: 8,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v move %v", i, tt.move), func(t *testing.T) {
+			got, err := board.At(tt.move)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectBoard, got.String())
+			assert.Equal(t, tt.expectPlayer, got.CurrentPlayer())
+			assert.Equal(t, tt.expectOver, got.GameOver())
+			assert.Len(t, got.Events(), tt.expectEvents)
+			assert.Equal(t, code, got.Code())
+		})
+	}
+}
+
+func TestRebuildRejectsInvalidEvents(t *testing.T) {
+	tests := []struct {
+		events []Event
+	}{
+		{events: []Event{{Type: "nope"}}},
+		{events: []Event{{Type: EventPlace, Player: whitePlayer, Position: "A1"}}},
+		{events: []Event{{Type: EventPlace, Player: blackPlayer, Position: "Z9"}}},
+		{events: []Event{{Type: EventPass, Player: whitePlayer}}},
+		{events: []Event{{Type: EventUndo}}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			_, err := Rebuild(4, tt.events)
+			assert.Error(t, err)
+		})
+	}
+}
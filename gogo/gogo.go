@@ -1,14 +1,10 @@
 package gogo
 
 import (
-	"bytes"
 	"fmt"
 	"math/rand"
-	"strconv"
-	"strings"
+	"sort"
 	"time"
-
-	"github.com/davecgh/go-spew/spew"
 )
 
 const (
@@ -18,93 +14,146 @@ const (
 	emptySpace rune = 'X'
 	blackPiece rune = 'B'
 	whitePiece rune = 'W'
+	redPiece   rune = 'R'
+	greenPiece rune = 'G'
 
 	blackPlayer string = "black"
 	whitePlayer string = "white"
+	redPlayer   string = "red"
+	greenPlayer string = "green"
+
+	codeLen int = 4
 
-	charset string = "ABCDEFGHIJKLMNOPQRSTUVWXYZ3456789"
-	codeLen int    = 4
+	// passMove is the index recorded for a pass
+	passMove int = -1
 )
 
 type Board struct {
-	size          int
+	width         int
+	height        int
 	code          string
 	board         []rune
 	nextPiece     rune
 	currentPlayer string
 	rand          *rand.Rand
+	moves         []move
+	prisoners     map[rune]int
+	events        []Event
+	endReason     EventType
+	loser         string
+	winner        string
+	dead          map[int]bool
+	now           func() time.Time
+	ko            int
+	start         *position
+	topology      Topology
+	colours       []rune
+	rules         Rules
+	komi          *float64
+	handicap      int
+	history       []boardState
+	logger        Logger
+	seats         map[rune]Player
+	conditional   map[rune][]ConditionalMove
+	clock         *clockRecord
 }
 
-// NewBoard ...
-func NewBoard(boardSize int) (Board, error) {
-	if boardSize < MinBoardSize {
-		return Board{}, fmt.Errorf("%q smaller minimum board size %q", boardSize, MinBoardSize)
-	}
+// position is a snapshot of the board that a game's history starts from,
+// for boards that were loaded from a position rather than played from
+// the start.
+type position struct {
+	board      []rune
+	nextPiece  rune
+	prisoners  map[rune]int
+	ko         int
+	moveNumber int
+}
 
-	if boardSize > MaxBoardSize {
-		return Board{}, fmt.Errorf("%q larger than maximum board size %q", boardSize, MaxBoardSize)
-	}
+// move is a single stone placed on the board ( or a pass ), along with
+// the stones it captured, so that a game can be replayed from the start.
+type move struct {
+	idx      int
+	piece    rune
+	captured []int
+}
 
-	return Board{
-		size:          boardSize,
-		board:         buildBoard(boardSize),
-		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
-		currentPlayer: blackPlayer,
-		nextPiece:     blackPiece,
-	}, nil
+// Option configures a board when it's created.
+type Option func(*Board) error
+
+// NewBoard ...
+func NewBoard(boardSize int, opts ...Option) (Board, error) {
+	return NewRectBoard(boardSize, boardSize, opts...)
 }
 
-// String ...
-func (b Board) String() string {
-	if b.board == nil {
-		return "Invalid Board"
-	}
+// NewRectBoard creates a board that's width columns wide and height rows
+// tall. Both must be between MinBoardSize and MaxBoardSize.
+func NewRectBoard(width, height int, opts ...Option) (Board, error) {
+	for _, boardSize := range []int{width, height} {
+		if boardSize < MinBoardSize {
+			return Board{}, fmt.Errorf("%w, %v is smaller than the minimum of %v", ErrBoardSize, boardSize, MinBoardSize)
+		}
 
-	sb := bytes.NewBuffer(nil)
-	vert := "%s "
-	if b.size > 9 {
-		vert = "%2s "
+		if boardSize > MaxBoardSize {
+			return Board{}, fmt.Errorf("%w, %v is larger than the maximum of %v", ErrBoardSize, boardSize, MaxBoardSize)
+		}
 	}
 
-	for i := b.size; i >= 1; i-- {
-		sb.WriteString(fmt.Sprintf(vert, fmt.Sprintf("%v", i)))
-		for j := 1; j <= b.size; j++ {
-			idx := b.coordsToIdx(i, j)
-			fmt.Printf("(%v,%v) -> %v,%q", i, j, idx, string(b.board[idx]))
-			if j == 1 {
-				sb.WriteRune(b.board[idx])
-			} else {
-				sb.WriteString(fmt.Sprintf("%2s", string(b.board[idx])))
-			}
-			fmt.Printf("\t")
+	b := newBoard(width, height)
+	b.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, opt := range opts {
+		if err := opt(&b); err != nil {
+			return Board{}, err
 		}
-		fmt.Printf("\n")
-		sb.WriteString("\n")
 	}
-
-	if b.size > 9 {
-		sb.WriteString(fmt.Sprintf("%4s", "A"))
-	} else {
-		sb.WriteString(fmt.Sprintf("%3s", "A"))
+	if err := b.checkSeats(); err != nil {
+		return Board{}, err
 	}
+	if err := b.placeHandicap(); err != nil {
+		return Board{}, err
+	}
+	return b, nil
+}
 
-	for i := 1; i < b.size; i++ {
-		sb.WriteString(fmt.Sprintf("%2s", string(rune(65+i))))
+// newBoard returns an empty board with black to play.
+func newBoard(width, height int) Board {
+	return Board{
+		width:         width,
+		height:        height,
+		board:         buildBoard(width, height),
+		currentPlayer: blackPlayer,
+		nextPiece:     blackPiece,
+		prisoners:     map[rune]int{},
+		dead:          map[int]bool{},
+		now:           time.Now,
+		ko:            -1,
 	}
+}
+
+// Size returns the width and height of the board.
+func (b Board) Size() (int, int) {
+	return b.width, b.height
+}
 
-	return sb.String()
+// String renders the board using the default TextOptions; empty points
+// are shown as 'X' and columns are lettered from A.
+func (b Board) String() string {
+	return b.Text(TextOptions{})
 }
 
-// Code ...
+// Code returns the code the game is saved and joined by, giving the
+// board one from DefaultCodes the first time it's asked for.
 func (b *Board) Code() string {
 	if len(b.code) > 0 {
 		return b.code
 	}
-	c := make([]byte, codeLen)
-	for i := range c {
-		c[i] = charset[b.rand.Intn(len(charset))]
+
+	code, err := DefaultCodes.New()
+	// if the codes are running out, longer ones are drawn from
+	// DefaultCodes' crypto/rand source until one is free
+	for n := 2 * codeLen; err != nil; n++ {
+		code, err = DefaultCodes.newOfLength(n)
 	}
-	b.code = string(c)
+	b.code = code
 	return b.code
 }
 
@@ -113,27 +162,185 @@ func (b *Board) CurrentPlayer() string {
 	return b.currentPlayer
 }
 
-// Place ...
-func (b *Board) Place(input string) (Result, error) {
-	if len(input) < 2 {
-		return Result{}, fmt.Errorf("invalid input %q", input)
+// Play places the current player's stone at a position written in A1
+// notation, like "C4".
+func (b *Board) Play(input string) (Result, error) {
+	p, err := b.parsePoint(input)
+	if err != nil {
+		err = &MoveError{Player: b.currentPlayer, Name: input, Err: err}
+		b.log(LevelWarn, logReject, "player", b.currentPlayer, "point", input, "error", err)
+		return Result{}, err
 	}
+	return b.Place(p)
+}
 
-	idx, err := b.canPlaceAt(input)
+// Place puts the current player's stone at p.
+func (b *Board) Place(p Point) (Result, error) {
+	var idx int
+	var err error
+	if b.GameOver() {
+		err = b.moveError(p, ErrGameOver)
+	} else {
+		idx, err = b.canPlaceAt(p)
+	}
 	if err != nil {
+		b.log(LevelWarn, logReject, "player", b.currentPlayer, "point", b.pointName(p), "error", err)
 		return Result{}, err
 	}
-	b.board[idx] = b.nextPiece
 
-	numBlackPieces, numWhitePieces := b.advanceToNextTurn()
-	return Result{blackPieces: numBlackPieces, whitePieces: numWhitePieces}, nil
+	b.appendEvent(EventPlace, b.currentPlayer, b.pointName(p))
+	res := b.play(idx)
+	b.answer(&p)
+	return res, nil
+}
+
+// play puts the next piece at idx, removes anything it captured, and
+// hands the turn to the other player.
+func (b *Board) play(idx int) Result {
+	if idx == passMove {
+		return b.pass()
+	}
+
+	piece := b.nextPiece
+	b.history = b.positions()
+	b.board[idx] = piece
+	captured := []int{}
+	if b.Rules().Captures() {
+		captured = b.capture(idx)
+	}
+	b.moves = append(b.moves, move{idx: idx, piece: piece, captured: captured})
+	b.log(LevelInfo, logPlace, "player", b.currentPlayer, "point", b.idxToInput(idx), "move", b.MoveNumber())
+	if len(captured) > 0 {
+		names := make([]string, 0, len(captured))
+		for _, c := range captured {
+			names = append(names, b.idxToInput(c))
+		}
+		b.log(LevelInfo, logCapture, "player", b.currentPlayer, "points", names, "count", len(captured))
+	}
+
+	// a single stone that captured a single stone, and is left with only
+	// the one liberty where the captured stone was, can be retaken
+	// straight away; that's ko, so the retake is forbidden for a turn
+	b.ko = -1
+	if len(captured) == 1 && b.board[idx] == piece {
+		if str := b.stringAt(idx); len(str) == 1 && b.liberties(str) == 1 {
+			b.ko = captured[0]
+			b.log(LevelInfo, logKo, "point", b.idxToInput(b.ko))
+		}
+	}
+
+	b.history = append(b.history, boardState{board: string(b.board), toMove: b.nextColour(piece)})
+
+	if winner := b.Rules().Winner(b, b.idxToPoint(idx)); winner != "" {
+		b.endReason = endWin
+		b.winner = winner
+	}
+
+	return b.advanceToNextTurn()
+}
+
+// capture removes any strings left without liberties by the stone just
+// placed at idx. Opposing strings are removed first, then the placed
+// stone's own string if it has no liberties left ( self-capture ). The
+// removed indexes are returned.
+func (b *Board) capture(idx int) []int {
+	piece := b.board[idx]
+	captured := []int{}
+
+	for _, n := range b.adjacent(idx) {
+		if p := b.board[n]; p == emptySpace || p == piece {
+			continue
+		}
+		str := b.stringAt(n)
+		if b.liberties(str) == 0 {
+			captured = append(captured, b.remove(str)...)
+			b.prisoners[piece] += len(str)
+		}
+	}
+
+	if str := b.stringAt(idx); b.liberties(str) == 0 {
+		captured = append(captured, b.remove(str)...)
+		b.prisoners[b.nextColour(piece)] += len(str)
+	}
+
+	return captured
+}
+
+// remove clears every point in str, returning str for convenience.
+func (b *Board) remove(str []int) []int {
+	for _, i := range str {
+		b.board[i] = emptySpace
+	}
+	return str
+}
+
+// players returns the colours playing, in turn order.
+func (b Board) players() []rune {
+	if len(b.colours) == 0 {
+		return []rune{blackPiece, whitePiece}
+	}
+	return b.colours
+}
+
+// nextColour returns the colour that plays after piece.
+func (b Board) nextColour(piece rune) rune {
+	players := b.players()
+	for i, p := range players {
+		if p == piece {
+			return players[(i+1)%len(players)]
+		}
+	}
+	return players[0]
+}
+
+// Prisoners returns how many stones black and white have captured.
+func (b Board) Prisoners() (int, int) {
+	return b.prisoners[blackPiece], b.prisoners[whitePiece]
+}
+
+// MoveNumber returns how many moves have been played so far.
+func (b Board) MoveNumber() int {
+	if b.start != nil {
+		return b.start.moveNumber + len(b.moves)
+	}
+	return len(b.moves)
+}
+
+// replay returns a fresh board of the same size with the first n moves
+// of this game's history played on it.
+func (b Board) replay(n int) Board {
+	out := newBoard(b.width, b.height)
+	out.topology, out.colours, out.rules = b.topology, b.colours, b.rules
+	out.komi, out.handicap = b.komi, b.handicap
+	if b.seats != nil {
+		out.seats = map[rune]Player{}
+		for c, p := range b.seats {
+			out.seats[c] = p
+		}
+	}
+	out.code, out.rand, out.now = b.code, b.rand, b.now
+	out.clock = b.clock
+	if b.start != nil {
+		out.start = b.start
+		copy(out.board, b.start.board)
+		out.nextPiece = b.start.nextPiece
+		out.currentPlayer = colourToPlayer(out.nextPiece)
+		out.ko = b.start.ko
+		for p, n := range b.start.prisoners {
+			out.prisoners[p] = n
+		}
+	}
+	for _, m := range b.moves[:n] {
+		out.play(m.idx)
+	}
+	out.logger = b.logger
+	return out
 }
 
 // validCoordinates ...
 func (b Board) validCoordinates(i, j int) bool {
-	//fmt.Printf("validCoordinates(%v, %v) =>  len(b.board): %v\n", i, j, idx, len(b.board))
-	return i > 0 && j > 0 && i <= b.size && j <= b.size
-	// if i < 0 || j < 0 || i > b.size ||
+	return i > 0 && j > 0 && i <= b.height && j <= b.width
+	// if i < 0 || j < 0 || i > b.height ||
 	// idx := b.coordsToIdx(i, j)
 	// return idx > 0 && idx < len(b.board)
 }
@@ -144,29 +351,22 @@ func (b Board) pieceAt(i, j int) coord {
 		return coord{}
 	}
 	idx := b.coordsToIdx(i, j)
-	fmt.Printf("board: \n%v\n", b.String())
-
-	fmt.Printf("pieceAt(%v,%v) => %v (idx: %v, size: %v)\n", i, j, string(b.board[idx]), idx, b.size)
+	b.log(LevelDebug, "pieceAt", "row", i, "column", j, "piece", string(b.board[idx]), "idx", idx)
 	return coord{i, j, b.board[idx]}
 }
 
 // getNeighbours ...
 func (b Board) getNeighbours(i, j int) []coord {
-	look := []coord{
-		{x: i - 1, y: j},
-		{x: i + 1, y: j},
-		{x: i, y: j - 1},
-		{x: i, y: j + 1},
-	}
 	var zero coord
 	current := b.pieceAt(i, j)
-	fmt.Printf("checking for neighbours of (%v,%v)\n", i, j)
+	if current.SamePos(zero) {
+		return []coord{}
+	}
 	neighbours := []coord{}
-	for _, l := range look {
-		check := b.pieceAt(l.x, l.y)
-		//fmt.Printf("check == zero? %v\n", check == zero)
-		if !check.SamePos(zero) && check.val == current.val {
-			fmt.Printf("\tneighbour (%v,%v) -> %v\n", l.x, l.y, check)
+	for _, n := range b.adjacent(b.coordsToIdx(i, j)) {
+		check := coordFromIndex(n, b.width, b.height, b.board[n])
+		if check.val == current.val {
+			b.log(LevelDebug, "getNeighbours", "row", i, "column", j, "neighbour", check.point())
 			neighbours = append(neighbours, check)
 		}
 	}
@@ -174,29 +374,86 @@ func (b Board) getNeighbours(i, j int) []coord {
 	return neighbours
 }
 
-// advanceToNextTurn ...
-func (b *Board) advanceToNextTurn() (int, int) {
-	if b.nextPiece == blackPiece {
-		b.nextPiece = whitePiece
-		b.currentPlayer = whitePlayer
-	} else {
-		b.nextPiece = blackPiece
-		b.currentPlayer = blackPlayer
-	}
+// advanceToNextTurn hands the turn to the next player, and returns the
+// Result of the move just recorded.
+func (b *Board) advanceToNextTurn() Result {
+	moved := b.nextPiece
+	b.nextPiece = b.nextColour(b.nextPiece)
+	b.currentPlayer = colourToPlayer(b.nextPiece)
 
-	numBlackPieces, numWhitePieces := 0, 0
+	pieces := map[string]int{}
+	for _, p := range b.players() {
+		pieces[colourToPlayer(p)] = 0
+	}
 	for _, p := range b.board {
-		if p == blackPiece {
-			numBlackPieces++
-		} else if p == whitePiece {
-			numWhitePieces++
+		if p != emptySpace {
+			pieces[colourToPlayer(p)]++
+		}
+	}
+
+	res := Result{
+		pieces:     pieces,
+		winner:     b.winner,
+		moveNumber: b.MoveNumber(),
+		player:     colourToPlayer(moved),
+		captured:   []Point{},
+		prisoners:  map[string]int{},
+		atari:      b.atari(),
+	}
+	for _, p := range b.players() {
+		res.prisoners[colourToPlayer(p)] = b.prisoners[p]
+	}
+	if last := b.moves[len(b.moves)-1]; last.idx != passMove {
+		for _, idx := range last.captured {
+			res.captured = append(res.captured, b.idxToPoint(idx))
 		}
 	}
+	if b.ko >= 0 {
+		ko := b.idxToPoint(b.ko)
+		res.ko = &ko
+	}
+	if game, ok := b.GameResult(); ok {
+		res.game = &game
+		b.logGameOver()
+	}
+	return res
+}
+
+// logGameOver logs how the game ended.
+func (b Board) logGameOver() {
+	if res, ok := b.GameResult(); ok {
+		b.log(LevelInfo, logGameOver, "reason", res.Reason, "result", res.String())
+	}
+}
 
-	return numBlackPieces, numWhitePieces
+// atari returns every string on the board with a single liberty.
+func (b Board) atari() []Group {
+	out := []Group{}
+	seen := map[int]bool{}
+	for idx, p := range b.board {
+		if p == emptySpace || seen[idx] {
+			continue
+		}
+		str := b.stringAt(idx)
+		for _, i := range str {
+			seen[i] = true
+		}
+		if b.liberties(str) != 1 {
+			continue
+		}
+
+		sort.Ints(str)
+		group := Group{Player: colourToPlayer(p)}
+		for _, i := range str {
+			group.Points = append(group.Points, b.idxToPoint(i))
+		}
+		out = append(out, group)
+	}
+	return out
 }
 
-// coordsToIdx ...
+// coordsToIdx converts a 1-based row i and column j to an index. The
+// points are stored a column at a time, from the bottom of each column.
 func (b *Board) coordsToIdx(i, j int) int {
 
 	i -= 1
@@ -204,94 +461,142 @@ func (b *Board) coordsToIdx(i, j int) int {
 
 	// swap to make things work
 	i, j = j, i
-	return ((i * b.size) + j)
+	return ((i * b.height) + j)
 }
 
 // inputToIdx ...
 func (b Board) inputToIdx(input string) (int, error) {
-	bits := strings.Split(input, "")
-	vert := bits[0]
-	horz := strings.Join(bits[1:], "")
-
-	y := strings.Index(charset, vert)
-	x, err := strconv.Atoi(horz)
+	p, err := b.parsePoint(input)
 	if err != nil {
-		return -1, fmt.Errorf("invalid horizontal position %q", horz)
+		return -1, err
+	}
+	return b.pointToIdx(p)
+}
+
+// idxToInput is the inverse of inputToIdx, turning an index back into a
+// position like "A1".
+func (b Board) idxToInput(idx int) string {
+	return b.pointName(b.idxToPoint(idx))
+}
+
+// parsePoint reads a position the way the board writes them; A1 notation
+// unless the topology names its own points.
+func (b Board) parsePoint(input string) (Point, error) {
+	if n, ok := b.Topology().(pointNamer); ok {
+		return n.parsePoint(input)
 	}
-	y += 1
+	return ParseA1(input)
+}
+
+// pointName is the inverse of parsePoint.
+func (b Board) pointName(p Point) string {
+	if n, ok := b.Topology().(pointNamer); ok {
+		return n.pointName(p)
+	}
+	return p.A1()
+}
 
-	if x <= 0 || x > b.size {
-		return -1, fmt.Errorf("invalid horizontal position %q for board size %v", input, b.size)
+// pointToIdx ...
+func (b Board) pointToIdx(p Point) (int, error) {
+	if p.Y < 0 || p.Y >= b.height {
+		return -1, fmt.Errorf("%w, invalid horizontal position %q for board size %vx%v", ErrOffBoard, p, b.width, b.height)
 	}
-	if y <= 0 || y > b.size {
-		return -1, fmt.Errorf("invalid vertical position %q for board size %v", input, b.size)
+	if p.X < 0 || p.X >= b.width {
+		return -1, fmt.Errorf("%w, invalid vertical position %q for board size %vx%v", ErrOffBoard, p, b.width, b.height)
 	}
+	return b.coordsToIdx(p.Y+1, p.X+1), nil
+}
 
-	return b.coordsToIdx(x, y), nil
+// idxToPoint ...
+func (b Board) idxToPoint(idx int) Point {
+	return Point{X: idx / b.height, Y: idx % b.height}
 }
 
-// canPlaceAt ...
-func (b Board) canPlaceAt(input string) (int, error) {
-	idx, err := b.inputToIdx(input)
+// canPlaceAt checks the current player can place at p, returning a
+// MoveError if they can't.
+func (b Board) canPlaceAt(p Point) (int, error) {
+	idx, err := b.pointToIdx(p)
 	if err != nil {
-		return -1, fmt.Errorf("can't place at %q, %w", input, err)
+		return -1, b.moveError(p, err)
 	}
 
 	// simple check -- is there a piece there?
-	if p := b.board[idx]; p != emptySpace {
-		return 0, fmt.Errorf("position %q already occupied", input)
+	if b.board[idx] != emptySpace {
+		return 0, b.moveError(p, ErrOccupied)
+	}
+
+	if err := b.Rules().Legal(&b, p); err != nil {
+		return 0, b.moveError(p, err)
 	}
 
 	return idx, nil
 }
 
-// getStrings ...
+// getString returns the indexes of the string of pieceType stones that
+// includes the point (x, y), or nil if there isn't one there.
 func (b Board) getString(x, y int, pieceType rune) []int {
-	fmt.Printf("getString(%v, %v, %v)\n", x, y, string(pieceType))
 	piece := b.pieceAt(x, y)
 
 	if piece.val != pieceType {
-		fmt.Printf("looking for %q, piece at (%v,%v) is %q\n", string(pieceType), x, y, string(piece.val))
+		b.log(LevelDebug, "getString", "row", x, "column", y, "want", string(pieceType), "piece", string(piece.val))
 		return nil
 	}
 
-	visited := map[coord]bool{}
-	queue := []coord{piece}
-
-	var current coord
+	return b.stringAt(b.coordsToIdx(x, y))
+}
 
-	for len(queue) > 0 && len(queue) < len(b.board) {
-		current, queue = pop(queue)
-		fmt.Printf("current (%v,%v -> %v): %v \n", current.x, current.y, string(current.val), current)
-		fmt.Printf("queue: %v\n", queue)
+// adjacent returns the indexes of the points next to idx, as decided by
+// the board's topology.
+func (b Board) adjacent(idx int) []int {
+	ns := b.Topology().Neighbours(b.idxToPoint(idx), b.width, b.height)
+	out := make([]int, 0, len(ns))
+	for _, n := range ns {
+		out = append(out, n.X*b.height+n.Y)
+	}
+	return out
+}
 
-		visited[current] = true
-		neighbours := b.getNeighbours(current.x, current.y)
-		fmt.Printf("neighbours: \n%v\n", neighbours)
+// stringAt returns the sorted indexes of every point connected to idx
+// through points of the same state.
+func (b Board) stringAt(idx int) []int {
+	val := b.board[idx]
+	visited := map[int]bool{idx: true}
+	queue := []int{idx}
+	str := []int{}
 
-		fmt.Printf("\n----------\n")
+	var current int
+	for len(queue) > 0 {
+		current, queue = pop(queue)
+		str = append(str, current)
 
-		for _, n := range neighbours {
-			if _, ok := visited[n]; !ok {
+		for _, n := range b.adjacent(current) {
+			if !visited[n] && b.board[n] == val {
+				visited[n] = true
 				queue = append(queue, n)
 			}
 		}
-
 	}
 
-	fmt.Printf("queue: \n")
-	spew.Dump(queue)
-
-	fmt.Printf("\ncurrent: %v\n", current)
-	fmt.Printf("visited: \n")
-	spew.Dump(visited)
+	sort.Ints(str)
+	return str
+}
 
-	return nil
+// liberties counts the distinct empty points next to the string str.
+func (b Board) liberties(str []int) int {
+	libs := map[int]bool{}
+	for _, i := range str {
+		for _, n := range b.adjacent(i) {
+			if b.board[n] == emptySpace {
+				libs[n] = true
+			}
+		}
+	}
+	return len(libs)
 }
 
 // buildBoard ...
-func buildBoard(size int) []rune {
-	len := size * size
+func buildBoard(width, height int) []rune {
+	len := width * height
 	arr := make([]rune, len)
 	for i := 0; i < len; i++ {
 		arr[i] = 'X'
@@ -299,43 +604,46 @@ func buildBoard(size int) []rune {
 	return arr
 }
 
+// coord is a 1-based (row, column) position on the board, along with
+// whatever is at that position.
 type coord struct {
 	x, y int
 	val  rune
 }
 
 // coordFromIndex ...
-func coordFromIndex(idx, size int, val rune) coord {
-	if idx < 0 || idx > size {
+func coordFromIndex(idx, width, height int, val rune) coord {
+	if idx < 0 || idx >= width*height {
 		return coord{}
 	}
-	x := idx / size
-	y := idx % size
+	// indexes run up each column in turn, see coordsToIdx
+	return coord{x: idx%height + 1, y: idx/height + 1, val: val}
+}
 
-	return coord{x: x, y: y, val: val}
+// point converts the 1-based (row, column) coord to a Point.
+func (c coord) point() Point {
+	return Point{X: c.y - 1, Y: c.x - 1}
 }
 
 // String ...
 func (c coord) String() string {
-	x := string(rune(65 + c.x))
-	return fmt.Sprintf("{%v%d: %v}", x, c.y, string(c.val))
+	return fmt.Sprintf("{%v: %v}", c.point(), string(c.val))
 }
 
 // AsPosition ...
 func (c coord) AsPosition() string {
-	x := string(rune(65 + c.x))
-	return fmt.Sprintf("%s%v", x, c.y)
+	return c.point().A1()
 }
 
 // Index ...
-func (c coord) Index(size int) int {
+func (c coord) Index(height int) int {
 	i := c.x - 1
 	j := c.y - 1
 
 	// swap to make things work
 	i, j = j, i
 
-	return ((i * size) + j)
+	return ((i * height) + j)
 }
 
 // SamePos ...
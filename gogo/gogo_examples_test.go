@@ -29,7 +29,7 @@ package gogo
 // 	// Points on the board are referenced on the horizontal axis by
 // 	// letters, and on the vertical axis by numbers. 'A0' is the bottom
 // 	// left position on the board, and E5 is the top right on a 5x5 board.
-// 	result, err := board.Place("A0")
+// 	result, err := board.Play("A0")
 // 	// Err will be non-nil if the placement was invalid, such as
 // 	// attempting to place on top of another piece, or attempting to
 // 	// place a piece off the board.
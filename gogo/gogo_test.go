@@ -1,6 +1,8 @@
 package gogo
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -88,31 +90,132 @@ func TestCreateGameBoard(t *testing.T) {
 	}
 }
 
-func TestBoardsGenerateBoardCode(t *testing.T) {
+func TestCreateRectBoard(t *testing.T) {
 	tests := []struct {
-		seed   int64
-		expect string
+		width  int
+		height int
+		inputs []string
+		output string
+		ok     bool
 	}{
-		{2, "KMYC"},
-		{3, "5RGY"},
+		{width: 3, height: 9},
+		{width: 9, height: 3},
+		{width: 27, height: 9},
+		{width: 9, height: 27},
+		{
+			width:  5,
+			height: 4,
+			output: `4 X X X X X
+3 X X X X X
+2 X X X X X
+1 X X X X X
+  A B C D E`,
+			ok: true,
+		},
+		// white's corner stone on E1 is captured against the right edge
+		{
+			width:  5,
+			height: 4,
+			inputs: []string{"D1", "E1", "A4", "B4", "E2"},
+			output: `4 B W X X X
+3 X X X X X
+2 X X X X B
+1 X X X B X
+  A B C D E`,
+			ok: true,
+		},
+		{
+			width:  4,
+			height: 6,
+			inputs: []string{"A6", "D1"},
+			output: `6 B X X X
+5 X X X X
+4 X X X X
+3 X X X X
+2 X X X X
+1 X X X W
+  A B C D`,
+			ok: true,
+		},
 	}
 
 	for i, x := range tests {
 		tt := x
-		t.Run(fmt.Sprintf("test %v seed %v expect %v", i, tt.seed, tt.expect), func(t *testing.T) {
-			board, err := NewBoard(9)
+		t.Run(fmt.Sprintf("test %v size %vx%v ok %v", i, tt.width, tt.height, tt.ok), func(t *testing.T) {
+			board, err := NewRectBoard(tt.width, tt.height)
+			if !tt.ok {
+				assert.Error(t, err)
+				return
+			}
 			require.NoError(t, err)
 
-			board.rand = rand.New(rand.NewSource(tt.seed))
-			code := board.Code()
+			for _, in := range tt.inputs {
+				_, err := board.Play(in)
+				require.NoError(t, err)
+			}
+
+			w, h := board.Size()
+			assert.Equal(t, tt.width, w)
+			assert.Equal(t, tt.height, h)
+			assert.Equal(t, tt.output, board.String())
+
+			// rectangular boards survive SGF and JSON round trips
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, board.EncodeSGF(buf))
+			fromSGF, err := DecodeSGF(buf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.output, fromSGF.String())
 
-			assert.Len(t, code, 4)
-			assert.Equal(t, tt.expect, code)
-			assert.Equal(t, tt.expect, board.Code())
+			data, err := json.Marshal(&board)
+			require.NoError(t, err)
+			var fromJSON Board
+			require.NoError(t, json.Unmarshal(data, &fromJSON))
+			assert.Equal(t, tt.output, fromJSON.String())
 		})
 	}
 }
 
+func TestBoardsGenerateBoardCode(t *testing.T) {
+	seen := map[string]bool{}
+	for _, seed := range []int64{2, 2, 3} {
+		board, err := NewBoard(9)
+		require.NoError(t, err)
+
+		board.rand = rand.New(rand.NewSource(seed))
+		code := board.Code()
+
+		assert.Len(t, code, codeLen)
+		assert.Empty(t, strings.Trim(code, UnambiguousAlphabet), "code %q", code)
+		assert.Equal(t, code, board.Code())
+		assert.True(t, DefaultCodes.InUse(code))
+		assert.False(t, seen[code], "boards with the same seed share code %q", code)
+		seen[code] = true
+		DefaultCodes.Release(code)
+	}
+}
+
+func TestBoardsGenerateLongerCodesWhenShortOnesRunOut(t *testing.T) {
+	defer func(g *CodeGenerator) { DefaultCodes = g }(DefaultCodes)
+	codes, err := NewCodeGenerator(WithCodeLength(1), WithAlphabet("AB"), WithCodeSource(rand.New(rand.NewSource(7))))
+	require.NoError(t, err)
+	require.NoError(t, codes.Reserve("A"))
+	require.NoError(t, codes.Reserve("B"))
+	DefaultCodes = codes
+
+	board, err := NewBoard(9)
+	require.NoError(t, err)
+	code := board.Code()
+	assert.Len(t, code, 2*codeLen)
+	assert.True(t, codes.InUse(code))
+
+	// the longer code comes from the generator's source, not the board's
+	same, err := NewCodeGenerator(WithCodeLength(2*codeLen), WithAlphabet("AB"), WithCodeSource(rand.New(rand.NewSource(7))))
+	require.NoError(t, err)
+	expect, err := same.New()
+	require.NoError(t, err)
+	assert.Equal(t, expect, code)
+}
+
 func TestPlacePieceOnBoard(t *testing.T) {
 	tests := []struct {
 		size   int
@@ -376,7 +479,7 @@ func TestPlacePieceOnBoard(t *testing.T) {
 				require.NoError(t, err)
 
 				var res Result
-				res, err = board.Place(tt.place)
+				res, err = board.Play(tt.place)
 
 				if !tt.valid {
 					assert.Error(t, err, "expected error for input %q", tt.place)
@@ -470,7 +573,7 @@ func TestHandleAlternatingPlayerInputs(t *testing.T) {
 				var gotBlackPieces int
 
 				for _, in := range tt.inputs {
-					res, err := board.Place(in)
+					res, err := board.Play(in)
 					if err != nil {
 						merr = multierror.Append(merr, err)
 					}
@@ -526,7 +629,7 @@ func TestFindPieceStringOnBoard(t *testing.T) {
 				b.board[5] = blackPiece
 				b.board[9] = blackPiece
 			},
-			check:         coord{2, 1, blackPiece},
+			check:         coord{2, 2, blackPiece},
 			expectStrings: []int{5, 9},
 		},
 	}
@@ -539,7 +642,7 @@ func TestFindPieceStringOnBoard(t *testing.T) {
 
 			tt.boardState(&board)
 
-			// _, err = board.Place(tt.check.AsPosition())
+			// _, err = board.Play(tt.check.AsPosition())
 			// require.NoError(t, err)
 
 			gotStrings := board.getString(tt.check.x, tt.check.y, tt.check.val)
@@ -548,6 +651,57 @@ func TestFindPieceStringOnBoard(t *testing.T) {
 	}
 }
 
+// koSetup leaves black having just taken a ko at C2, so white can't
+// play B2 straight away.
+var koSetup = []string{"B3", "C3", "A2", "D2", "B1", "C1", "D4", "B2", "C2"}
+
+func TestKoCannotBeRetakenImmediately(t *testing.T) {
+	tests := []struct {
+		inputs      []string
+		valid       bool
+		expectBoard string
+	}{
+		{inputs: append(append([]string{}, koSetup...), "B2")},
+		// once both players have played elsewhere, the ko can be retaken
+		{
+			inputs: append(append([]string{}, koSetup...), "A4", "A3", "B2"),
+			valid:  true,
+			expectBoard: `4 W X X B
+3 B B W X
+2 B W X W
+1 X B W X
+  A B C D`,
+		},
+		// so does black passing instead of answering a move elsewhere
+		{
+			inputs: append(append([]string{}, koSetup...), "A4", "pass", "B2"),
+			valid:  true,
+			expectBoard: `4 W X X B
+3 X B W X
+2 B W X W
+1 X B W X
+  A B C D`,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v inputs %v", i, strings.Join(tt.inputs, "_")), func(t *testing.T) {
+			board, err := NewBoard(4)
+			require.NoError(t, err)
+
+			err = play(&board, tt.inputs)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectBoard, board.String())
+		})
+	}
+}
+
 /*
 
    A4 B4 C4 D4    1,4  2,4  3,4  4,4    3  7  11 15
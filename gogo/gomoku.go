@@ -0,0 +1,63 @@
+package gogo
+
+// Gomoku is five in a row: players take turns placing stones, nothing is
+// captured, and the first to get five in a row horizontally, vertically
+// or diagonally wins. Lines don't wrap around the edges of boards with a
+// Torus or Cylinder topology, and a GraphBoard has no lines, so nobody
+// wins Gomoku on one.
+type Gomoku struct {
+	// ExactlyFive only counts lines of exactly five stones, so six or
+	// more in a row don't win.
+	ExactlyFive bool
+}
+
+// Name ...
+func (g Gomoku) Name() string {
+	if g.ExactlyFive {
+		return "gomoku-standard"
+	}
+	return "gomoku"
+}
+
+// Legal ...
+func (Gomoku) Legal(_ *Board, _ Point) error { return nil }
+
+// Captures ...
+func (Gomoku) Captures() bool { return false }
+
+// passResult is a draw, as nobody got five in a row.
+func (Gomoku) passResult(_ Board) GameResult {
+	return GameResult{Reason: ReasonDraw}
+}
+
+// Winner ...
+func (g Gomoku) Winner(b *Board, p Point) string {
+	if _, ok := b.Topology().(pointNamer); ok {
+		return ""
+	}
+	player, err := b.PieceAt(p)
+	if err != nil || player == "" {
+		return ""
+	}
+
+	for _, d := range []Point{{1, 0}, {0, 1}, {1, 1}, {1, -1}} {
+		n := 1 + g.count(b, p, d, player) + g.count(b, p, Point{-d.X, -d.Y}, player)
+		if n == 5 || (n > 5 && !g.ExactlyFive) {
+			return player
+		}
+	}
+	return ""
+}
+
+// count returns how many of player's stones are in a row from p, not
+// including p, heading in direction d.
+func (Gomoku) count(b *Board, p Point, d Point, player string) int {
+	n := 0
+	for {
+		p = Point{X: p.X + d.X, Y: p.Y + d.Y}
+		if got, err := b.PieceAt(p); err != nil || got != player {
+			return n
+		}
+		n++
+	}
+}
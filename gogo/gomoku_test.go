@@ -0,0 +1,176 @@
+package gogo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGomoku(t *testing.T) {
+	overline := []string{"A1", "A3", "B1", "B3", "C1", "C3", "E1", "E3", "F1", "F3", "D1"}
+
+	tests := []struct {
+		rules        Rules
+		actions      []string
+		expectWinner string
+		// a point and who should be on it at the end
+		checkPoint  string
+		checkPlayer string
+	}{
+		{rules: FreestyleGomoku, actions: []string{"A1", "A2", "B1", "B2", "C1", "C2", "D1"}},
+		{
+			rules:        FreestyleGomoku,
+			actions:      []string{"A1", "A2", "B1", "B2", "C1", "C2", "D1", "D2", "E1"},
+			expectWinner: blackPlayer,
+		},
+		{
+			rules:        FreestyleGomoku,
+			actions:      []string{"A1", "B1", "A2", "B2", "A3", "B3", "A4", "B4", "A5"},
+			expectWinner: blackPlayer,
+		},
+		{
+			rules:        FreestyleGomoku,
+			actions:      []string{"A1", "A9", "B2", "B9", "C3", "C9", "D4", "D9", "E5"},
+			expectWinner: blackPlayer,
+		},
+		{
+			rules:        FreestyleGomoku,
+			actions:      []string{"A5", "H1", "B4", "H2", "C3", "H3", "D2", "H4", "E1"},
+			expectWinner: blackPlayer,
+		},
+		// the winning stone doesn't have to be at the end of the line
+		{
+			rules:        FreestyleGomoku,
+			actions:      []string{"I9", "A1", "I8", "B1", "I7", "D1", "I5", "E1", "A9", "C1"},
+			expectWinner: whitePlayer,
+		},
+		// six in a row only wins freestyle
+		{rules: FreestyleGomoku, actions: overline, expectWinner: blackPlayer},
+		{rules: StandardGomoku, actions: overline},
+		// nothing is captured
+		{
+			rules:       FreestyleGomoku,
+			actions:     []string{"B1", "A1", "A2"},
+			checkPoint:  "A1",
+			checkPlayer: whitePlayer,
+		},
+		{
+			rules:       DefaultRules,
+			actions:     []string{"B1", "A1", "A2"},
+			checkPoint:  "A1",
+			checkPlayer: "",
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v actions %v", i, tt.rules.Name(), strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewBoard(9, WithRules(tt.rules))
+			require.NoError(t, err)
+
+			last := tt.actions[len(tt.actions)-1]
+			require.NoError(t, play(&board, tt.actions[:len(tt.actions)-1]))
+			res, err := board.Play(last)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectWinner, res.Winner())
+			assert.Equal(t, tt.expectWinner, board.Winner())
+			assert.Equal(t, tt.expectWinner != "", board.GameOver())
+
+			if tt.checkPoint != "" {
+				p, err := ParseA1(tt.checkPoint)
+				require.NoError(t, err)
+				got, err := board.PieceAt(p)
+				require.NoError(t, err)
+				assert.Equal(t, tt.checkPlayer, got)
+			}
+
+			// the rules are kept when the game's saved and loaded
+			data, err := json.Marshal(&board)
+			require.NoError(t, err)
+			var fromJSON Board
+			require.NoError(t, json.Unmarshal(data, &fromJSON))
+			assert.Equal(t, tt.rules, fromJSON.Rules())
+
+			store := NewMemoryStore()
+			require.NoError(t, store.Save(&board))
+			loaded, err := store.Load(board.Code())
+			require.NoError(t, err)
+			assert.Equal(t, tt.rules, loaded.Rules())
+			assert.Equal(t, tt.expectWinner, loaded.Winner())
+		})
+	}
+}
+
+func TestGomokuEndedByPassing(t *testing.T) {
+	for _, rules := range []Rules{FreestyleGomoku, StandardGomoku} {
+		board, err := NewBoard(9, WithRules(rules))
+		require.NoError(t, err)
+		require.NoError(t, play(&board, []string{"A1", "pass", "pass"}))
+
+		res, ok := board.GameResult()
+		require.True(t, ok, rules.Name())
+		assert.Equal(t, GameResult{Reason: ReasonDraw}, res, rules.Name())
+	}
+}
+
+// noTengen is a set of rules that doesn't allow the centre point to be
+// played.
+type noTengen struct {
+	Ruleset
+}
+
+func (noTengen) Name() string { return "no-tengen" }
+
+func (noTengen) Legal(b *Board, p Point) error {
+	w, h := b.Size()
+	if p.X == w/2 && p.Y == h/2 {
+		return fmt.Errorf("can't play in the centre")
+	}
+	return nil
+}
+
+func TestPlaceUsesRules(t *testing.T) {
+	board, err := NewBoard(9, WithRules(noTengen{DefaultRuleset}))
+	require.NoError(t, err)
+
+	_, err = board.Play("E5")
+	assert.Error(t, err)
+	_, err = board.Play("E4")
+	assert.NoError(t, err)
+
+	// rules that can't be loaded again can't be saved either
+	data, err := json.Marshal(&board)
+	require.NoError(t, err)
+	var loaded Board
+	assert.Error(t, json.Unmarshal(data, &loaded))
+}
+
+// bannedPoints is a set of rules that can't be compared with ==, as it
+// holds a slice.
+type bannedPoints struct {
+	Ruleset
+	points []Point
+}
+
+func (bannedPoints) Name() string { return "banned-points" }
+
+func TestUncomparableRules(t *testing.T) {
+	// comparing two of them panics, which happens when they're also the
+	// DefaultRules
+	defer func(r Rules) { DefaultRules = r }(DefaultRules)
+	DefaultRules = bannedPoints{Ruleset: DefaultRuleset, points: []Point{{X: 4, Y: 4}}}
+	board, err := NewBoard(9, WithRules(bannedPoints{Ruleset: DefaultRuleset, points: []Point{{X: 0, Y: 0}}}))
+	require.NoError(t, err)
+
+	assert.NotPanics(t, func() {
+		_, _ = json.Marshal(&board)
+		_ = board.EncodeSGF(io.Discard)
+		_ = NewMemoryStore().Save(&board)
+	})
+}
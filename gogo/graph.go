@@ -0,0 +1,242 @@
+package gogo
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Goban is anything Go can be played on. Board is the usual grid; a
+// GraphBoard plays on any undirected graph. Points are written however
+// the goban names them, like "C4" on a Board.
+type Goban interface {
+	Code() string
+	CurrentPlayer() string
+	Play(point string) (Result, error)
+	Pass() (Result, error)
+	Resign(player string) error
+	Timeout(player string) error
+	Forfeit(player string) error
+	Undo() error
+	ToggleDead(point string) error
+	GameOver() bool
+	GameResult() (GameResult, bool)
+	Events() []Event
+	MoveNumber() int
+	Prisoners() (int, int)
+	Score() (int, int)
+}
+
+var (
+	_ Goban = (*Board)(nil)
+	_ Goban = (*GraphBoard)(nil)
+)
+
+// Graph defines a board as a set of named points, and the pairs of points
+// that are next to each other. Edges go both ways.
+type Graph struct {
+	Points []string    `json:"points"`
+	Edges  [][2]string `json:"edges"`
+}
+
+// GraphBoard is a game of Go played on a Graph, using the same rules as a
+// Board: captures, ko, passing, dead stones and area scoring all follow
+// the edges of the graph. Points are played by name.
+type GraphBoard struct {
+	graph Graph
+	board Board
+}
+
+// NewGraphBoard checks the graph and creates an empty board from it.
+// Every point needs a unique, non-empty name, and edges must join two
+// different points of the graph. Options that change the topology are
+// ignored.
+func NewGraphBoard(g Graph, opts ...Option) (*GraphBoard, error) {
+	topology, err := newGraphTopology(g)
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBoard(len(g.Points), 1)
+	b.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, opt := range opts {
+		if err := opt(&b); err != nil {
+			return nil, err
+		}
+	}
+	if b.handicap > 0 {
+		return nil, fmt.Errorf("graph boards can't have a handicap")
+	}
+	if err := b.checkSeats(); err != nil {
+		return nil, err
+	}
+	b.topology = topology
+	return &GraphBoard{graph: g, board: b}, nil
+}
+
+// Graph returns the graph the board was created from.
+func (g *GraphBoard) Graph() Graph {
+	return g.graph
+}
+
+// PieceAt returns the player with a stone on the named point, or "" if
+// it's empty.
+func (g *GraphBoard) PieceAt(point string) (string, error) {
+	idx, err := g.board.inputToIdx(point)
+	if err != nil {
+		return "", err
+	}
+	if g.board.board[idx] == emptySpace {
+		return "", nil
+	}
+	return colourToPlayer(g.board.board[idx]), nil
+}
+
+// At rebuilds the game as it was after the given number of moves.
+func (g *GraphBoard) At(moveNumber int) (*GraphBoard, error) {
+	b, err := g.board.At(moveNumber)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphBoard{graph: g.graph, board: b}, nil
+}
+
+// Code ...
+func (g *GraphBoard) Code() string { return g.board.Code() }
+
+// CurrentPlayer ...
+func (g *GraphBoard) CurrentPlayer() string { return g.board.CurrentPlayer() }
+
+// Play places the current player's stone on the named point.
+func (g *GraphBoard) Play(point string) (Result, error) { return g.board.Play(point) }
+
+// Pass ...
+func (g *GraphBoard) Pass() (Result, error) { return g.board.Pass() }
+
+// Resign ...
+func (g *GraphBoard) Resign(player string) error { return g.board.Resign(player) }
+
+// Timeout ...
+func (g *GraphBoard) Timeout(player string) error { return g.board.Timeout(player) }
+
+// Forfeit ...
+func (g *GraphBoard) Forfeit(player string) error { return g.board.Forfeit(player) }
+
+// Undo ...
+func (g *GraphBoard) Undo() error { return g.board.Undo() }
+
+// ToggleDead ...
+func (g *GraphBoard) ToggleDead(point string) error { return g.board.ToggleDead(point) }
+
+// GameOver ...
+func (g *GraphBoard) GameOver() bool { return g.board.GameOver() }
+
+// GameResult ...
+func (g *GraphBoard) GameResult() (GameResult, bool) { return g.board.GameResult() }
+
+// Events ...
+func (g *GraphBoard) Events() []Event { return g.board.Events() }
+
+// MoveNumber ...
+func (g *GraphBoard) MoveNumber() int { return g.board.MoveNumber() }
+
+// Prisoners ...
+func (g *GraphBoard) Prisoners() (int, int) { return g.board.Prisoners() }
+
+// Score ...
+func (g *GraphBoard) Score() (int, int) { return g.board.Score() }
+
+// pointNamer is implemented by topologies that name their own points,
+// rather than using A1 notation.
+type pointNamer interface {
+	parsePoint(s string) (Point, error)
+	pointName(p Point) string
+}
+
+// graphTopology lays the points of a graph out in a single row, in the
+// order they were defined, so point i of the graph is Point{X: i}.
+type graphTopology struct {
+	names []string
+	index map[string]int
+	adj   [][]int
+}
+
+// newGraphTopology ...
+func newGraphTopology(g Graph) (graphTopology, error) {
+	if len(g.Points) == 0 {
+		return graphTopology{}, fmt.Errorf("a graph needs at least one point")
+	}
+
+	t := graphTopology{
+		names: g.Points,
+		index: make(map[string]int, len(g.Points)),
+		adj:   make([][]int, len(g.Points)),
+	}
+	for i, name := range g.Points {
+		if name == "" {
+			return graphTopology{}, fmt.Errorf("point %d has no name", i+1)
+		}
+		if _, ok := t.index[name]; ok {
+			return graphTopology{}, fmt.Errorf("point %q is defined twice", name)
+		}
+		t.index[name] = i
+	}
+
+	seen := map[[2]int]bool{}
+	for _, e := range g.Edges {
+		a, ok := t.index[e[0]]
+		if !ok {
+			return graphTopology{}, fmt.Errorf("edge %v-%v: unknown point %q", e[0], e[1], e[0])
+		}
+		b, ok := t.index[e[1]]
+		if !ok {
+			return graphTopology{}, fmt.Errorf("edge %v-%v: unknown point %q", e[0], e[1], e[1])
+		}
+		if a == b {
+			return graphTopology{}, fmt.Errorf("edge %v-%v joins a point to itself", e[0], e[1])
+		}
+		if a > b {
+			a, b = b, a
+		}
+		if seen[[2]int{a, b}] {
+			continue
+		}
+		seen[[2]int{a, b}] = true
+		t.adj[a] = append(t.adj[a], b)
+		t.adj[b] = append(t.adj[b], a)
+	}
+
+	return t, nil
+}
+
+// Name ...
+func (graphTopology) Name() string { return "graph" }
+
+// Neighbours ...
+func (t graphTopology) Neighbours(p Point, _, _ int) []Point {
+	if p.Y != 0 || p.X < 0 || p.X >= len(t.adj) {
+		return nil
+	}
+	out := make([]Point, 0, len(t.adj[p.X]))
+	for _, n := range t.adj[p.X] {
+		out = append(out, Point{X: n})
+	}
+	return out
+}
+
+// parsePoint ...
+func (t graphTopology) parsePoint(s string) (Point, error) {
+	i, ok := t.index[s]
+	if !ok {
+		return Point{}, fmt.Errorf("%w, unknown point %q", ErrBadCoordinate, s)
+	}
+	return Point{X: i}, nil
+}
+
+// pointName ...
+func (t graphTopology) pointName(p Point) string {
+	if p.Y != 0 || p.X < 0 || p.X >= len(t.names) {
+		return p.XY()
+	}
+	return t.names[p.X]
+}
@@ -0,0 +1,216 @@
+package gogo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hexFlower is a centre point surrounded by a ring of six.
+var hexFlower = Graph{
+	Points: []string{"c", "r1", "r2", "r3", "r4", "r5", "r6"},
+	Edges: [][2]string{
+		{"c", "r1"}, {"c", "r2"}, {"c", "r3"}, {"c", "r4"}, {"c", "r5"}, {"c", "r6"},
+		{"r1", "r2"}, {"r2", "r3"}, {"r3", "r4"}, {"r4", "r5"}, {"r5", "r6"}, {"r6", "r1"},
+	},
+}
+
+// gridGraph is the graph of a square board, with points named in A1
+// notation.
+func gridGraph(size int) Graph {
+	g := Graph{}
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			p := Point{x, y}
+			g.Points = append(g.Points, p.A1())
+			if x > 0 {
+				g.Edges = append(g.Edges, [2]string{Point{x - 1, y}.A1(), p.A1()})
+			}
+			if y > 0 {
+				g.Edges = append(g.Edges, [2]string{Point{x, y - 1}.A1(), p.A1()})
+			}
+		}
+	}
+	return g
+}
+
+// playGoban is play for anything that's a Goban.
+func playGoban(g Goban, actions []string) error {
+	for _, a := range actions {
+		var err error
+		switch {
+		case a == "pass":
+			_, err = g.Pass()
+		case a == "undo":
+			err = g.Undo()
+		case strings.HasPrefix(a, "dead:"):
+			err = g.ToggleDead(strings.TrimPrefix(a, "dead:"))
+		default:
+			_, err = g.Play(a)
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %w", a, err)
+		}
+	}
+	return nil
+}
+
+func TestCreateGraphBoard(t *testing.T) {
+	tests := []struct {
+		graph Graph
+		ok    bool
+	}{
+		{graph: Graph{}},
+		{graph: Graph{Points: []string{"a", ""}}},
+		{graph: Graph{Points: []string{"a", "a"}}},
+		{graph: Graph{Points: []string{"a", "b"}, Edges: [][2]string{{"a", "c"}}}},
+		{graph: Graph{Points: []string{"a", "b"}, Edges: [][2]string{{"a", "a"}}}},
+		{graph: Graph{Points: []string{"a"}}, ok: true},
+		// repeated edges are only counted once
+		{graph: Graph{Points: []string{"a", "b"}, Edges: [][2]string{{"a", "b"}, {"b", "a"}}}, ok: true},
+		{graph: hexFlower, ok: true},
+		// no upper limit like a Board has
+		{graph: gridGraph(30), ok: true},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v points %v ok %v", i, len(tt.graph.Points), tt.ok), func(t *testing.T) {
+			board, err := NewGraphBoard(tt.graph)
+			if !tt.ok {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.graph, board.Graph())
+			assert.Len(t, board.Code(), codeLen)
+			assert.Equal(t, blackPlayer, board.CurrentPlayer())
+		})
+	}
+}
+
+func TestPlayOnGraphBoard(t *testing.T) {
+	tests := []struct {
+		graph           Graph
+		actions         []string
+		expect          map[string]string
+		expectPrisoners [2]int
+		expectScore     [2]int
+	}{
+		{
+			graph:   hexFlower,
+			actions: []string{"r1", "c", "r2"},
+			expect:  map[string]string{"r1": blackPlayer, "c": whitePlayer, "r2": blackPlayer, "r3": ""},
+			// the empty points on the ring touch both colours
+			expectScore: [2]int{2, 1},
+		},
+		// surrounding the centre on all six sides captures it
+		{
+			graph:           hexFlower,
+			actions:         []string{"r1", "c", "r2", "pass", "r3", "pass", "r4", "pass", "r5", "pass", "r6"},
+			expect:          map[string]string{"c": "", "r6": blackPlayer},
+			expectPrisoners: [2]int{1, 0},
+			expectScore:     [2]int{7, 0},
+		},
+		{
+			graph:           Graph{Points: []string{"a", "b", "c"}, Edges: [][2]string{{"a", "b"}, {"b", "c"}}},
+			actions:         []string{"a", "c", "b"},
+			expect:          map[string]string{"a": blackPlayer, "b": blackPlayer, "c": ""},
+			expectPrisoners: [2]int{1, 0},
+			expectScore:     [2]int{3, 0},
+		},
+		// a stone marked dead counts for the other player
+		{
+			graph:       Graph{Points: []string{"a", "b", "c"}, Edges: [][2]string{{"a", "b"}, {"b", "c"}}},
+			actions:     []string{"a", "c", "pass", "pass", "dead:c"},
+			expect:      map[string]string{"a": blackPlayer, "b": "", "c": whitePlayer},
+			expectScore: [2]int{3, 0},
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewGraphBoard(tt.graph)
+			require.NoError(t, err)
+			require.NoError(t, playGoban(board, tt.actions))
+
+			for point, player := range tt.expect {
+				got, err := board.PieceAt(point)
+				require.NoError(t, err)
+				assert.Equal(t, player, got, "point %v", point)
+			}
+
+			gotBlack, gotWhite := board.Prisoners()
+			assert.Equal(t, tt.expectPrisoners, [2]int{gotBlack, gotWhite}, "prisoners")
+			gotBlack, gotWhite = board.Score()
+			assert.Equal(t, tt.expectScore, [2]int{gotBlack, gotWhite}, "score")
+
+			// events use the graph's names, so the game can be
+			// rebuilt from them
+			for _, e := range board.Events() {
+				if e.Type == EventPlace {
+					assert.Contains(t, tt.graph.Points, e.Position)
+				}
+			}
+			start, err := board.At(0)
+			require.NoError(t, err)
+			assert.Equal(t, 0, start.MoveNumber())
+		})
+	}
+}
+
+func TestGraphBoardErrors(t *testing.T) {
+	board, err := NewGraphBoard(hexFlower)
+	require.NoError(t, err)
+
+	_, err = board.Play("r7")
+	assert.Error(t, err)
+	_, err = board.PieceAt("A1")
+	assert.Error(t, err)
+
+	require.NoError(t, playGoban(board, []string{"c"}))
+	_, err = board.Play("c")
+	assert.Error(t, err)
+}
+
+func TestGridGraphMatchesBoard(t *testing.T) {
+	// includes a capture of B2
+	actions := []string{"B1", "B2", "A2", "A3", "C2", "C3", "D3", "D2", "B3", "B4", "pass", "pass"}
+
+	board, err := NewBoard(4)
+	require.NoError(t, err)
+	graph, err := NewGraphBoard(gridGraph(4))
+	require.NoError(t, err)
+
+	for _, g := range []Goban{&board, graph} {
+		require.NoError(t, playGoban(g, actions))
+	}
+
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			p := Point{x, y}
+			expect := ""
+			if piece := board.board[board.coordsToIdx(y+1, x+1)]; piece != emptySpace {
+				expect = colourToPlayer(piece)
+			}
+			got, err := graph.PieceAt(p.A1())
+			require.NoError(t, err)
+			assert.Equal(t, expect, got, "point %v", p)
+		}
+	}
+
+	expectBlack, expectWhite := board.Score()
+	gotBlack, gotWhite := graph.Score()
+	assert.Equal(t, expectBlack, gotBlack)
+	assert.Equal(t, expectWhite, gotWhite)
+
+	expectBlack, expectWhite = board.Prisoners()
+	gotBlack, gotWhite = graph.Prisoners()
+	assert.Equal(t, expectBlack, gotBlack)
+	assert.Equal(t, expectWhite, gotWhite)
+	assert.Equal(t, board.GameOver(), graph.GameOver())
+}
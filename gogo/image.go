@@ -0,0 +1,241 @@
+package gogo
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"strconv"
+)
+
+const (
+	// DefaultImageSize is the width and height in pixels used when
+	// ImageOptions.Size isn't set.
+	DefaultImageSize int = 400
+
+	// DefaultFrameDelay is the time each frame of an animated replay is
+	// shown for, in 100ths of a second.
+	DefaultFrameDelay int = 100
+)
+
+// palette indexes
+const (
+	woodIdx uint8 = iota
+	lineIdx
+	whiteIdx
+	greyIdx
+	redIdx
+	greenIdx
+)
+
+var boardPalette = color.Palette{
+	color.RGBA{0xdc, 0xb3, 0x5c, 0xff}, // wood
+	color.RGBA{0x00, 0x00, 0x00, 0xff}, // lines and black stones
+	color.RGBA{0xff, 0xff, 0xff, 0xff}, // white stones
+	color.RGBA{0x80, 0x80, 0x80, 0xff}, // stone outlines
+	color.RGBA{0xc0, 0x20, 0x20, 0xff}, // red stones
+	color.RGBA{0x20, 0x80, 0x30, 0xff}, // green stones
+}
+
+// digits is a tiny 3x5 bitmap font used for move numbers, so that
+// rendering only needs the standard library image packages.
+var digits = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", ".##", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", ".#.", ".#.", ".#."},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+// ImageOptions controls how a board is rasterised.
+type ImageOptions struct {
+	// Size is the width and height of the image in pixels.
+	Size int
+	// MoveNumbers draws the number of the move that placed each stone
+	// on top of it.
+	MoveNumbers bool
+	// Delay is how long each frame of an animated replay is shown, in
+	// 100ths of a second.
+	Delay int
+}
+
+// withDefaults ...
+func (o ImageOptions) withDefaults() ImageOptions {
+	if o.Size <= 0 {
+		o.Size = DefaultImageSize
+	}
+	if o.Delay <= 0 {
+		o.Delay = DefaultFrameDelay
+	}
+	return o
+}
+
+// Image draws the current position of the board. The longer side of the
+// board is opts.Size pixels; the shorter side of a rectangular board is
+// scaled to match. On boards whose edges join up, the lines run off the
+// edge of the image to show that they wrap around.
+func (b Board) Image(opts ImageOptions) *image.Paletted {
+	opts = opts.withDefaults()
+	if b.board == nil {
+		img := image.NewPaletted(image.Rect(0, 0, opts.Size, opts.Size), boardPalette)
+		draw.Draw(img, img.Bounds(), &image.Uniform{boardPalette[woodIdx]}, image.Point{}, draw.Src)
+		return img
+	}
+
+	longest := b.width
+	if b.height > longest {
+		longest = b.height
+	}
+	cell := opts.Size / (longest + 1)
+	imgWidth := opts.Size - cell*(longest-b.width)
+	imgHeight := opts.Size - cell*(longest-b.height)
+
+	img := image.NewPaletted(image.Rect(0, 0, imgWidth, imgHeight), boardPalette)
+	draw.Draw(img, img.Bounds(), &image.Uniform{boardPalette[woodIdx]}, image.Point{}, draw.Src)
+
+	left := (imgWidth - cell*(b.width-1)) / 2
+	top := (imgHeight - cell*(b.height-1)) / 2
+	right := left + cell*(b.width-1)
+	bottom := top + cell*(b.height-1)
+
+	lineLeft, lineRight := left, right+1
+	lineTop, lineBottom := top, bottom+1
+	wrapX, wrapY := b.wraps()
+	if wrapX {
+		lineLeft, lineRight = 0, imgWidth
+	}
+	if wrapY {
+		lineTop, lineBottom = 0, imgHeight
+	}
+
+	for i := 0; i < b.height; i++ {
+		pos := top + i*cell
+		fillRect(img, lineLeft, pos, lineRight, pos+1, lineIdx)
+	}
+	for i := 0; i < b.width; i++ {
+		pos := left + i*cell
+		fillRect(img, pos, lineTop, pos+1, lineBottom, lineIdx)
+	}
+
+	numbers := b.moveNumbers()
+	radius := cell * 9 / 20
+	for idx, piece := range b.board {
+		if piece == emptySpace {
+			continue
+		}
+		p := b.idxToPoint(idx)
+		cx := left + p.X*cell
+		cy := top + (b.height-1-p.Y)*cell
+
+		fill, text := lineIdx, whiteIdx
+		switch piece {
+		case whitePiece:
+			fillCircle(img, cx, cy, radius+1, greyIdx)
+			fill, text = whiteIdx, lineIdx
+		case redPiece:
+			fill = redIdx
+		case greenPiece:
+			fill = greenIdx
+		}
+		fillCircle(img, cx, cy, radius, fill)
+
+		if opts.MoveNumbers && numbers[idx] > 0 {
+			drawNumber(img, cx, cy, radius, numbers[idx], text)
+		}
+	}
+
+	return img
+}
+
+// EncodePNG writes the current position of the board as a PNG.
+func (b Board) EncodePNG(w io.Writer, opts ImageOptions) error {
+	return png.Encode(w, b.Image(opts))
+}
+
+// EncodeGIF writes an animated GIF that replays the game so far, one
+// frame per move, starting from the empty board.
+func (b Board) EncodeGIF(w io.Writer, opts ImageOptions) error {
+	opts = opts.withDefaults()
+
+	anim := &gif.GIF{}
+	for i := 0; i <= len(b.moves); i++ {
+		frame := b.replay(i)
+		anim.Image = append(anim.Image, frame.Image(opts))
+		anim.Delay = append(anim.Delay, opts.Delay)
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+// moveNumbers maps each occupied point to the number of the move that
+// put the stone currently there.
+func (b Board) moveNumbers() map[int]int {
+	out := map[int]int{}
+	offset := b.MoveNumber() - len(b.moves)
+	for i, m := range b.moves {
+		for _, c := range m.captured {
+			delete(out, c)
+		}
+		if m.idx != passMove && b.board[m.idx] == m.piece {
+			out[m.idx] = offset + i + 1
+		}
+	}
+	return out
+}
+
+// fillRect ...
+func fillRect(img *image.Paletted, x0, y0, x1, y1 int, c uint8) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			img.SetColorIndex(x, y, c)
+		}
+	}
+}
+
+// fillCircle ...
+func fillCircle(img *image.Paletted, cx, cy, r int, c uint8) {
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r {
+				img.SetColorIndex(cx+x, cy+y, c)
+			}
+		}
+	}
+}
+
+// drawNumber draws n centred on (cx, cy), scaled to fit inside a stone
+// of the given radius.
+func drawNumber(img *image.Paletted, cx, cy, radius, n int, c uint8) {
+	text := strconv.Itoa(n)
+
+	// each glyph is 3 pixels wide with a 1 pixel gap between glyphs
+	width := len(text)*4 - 1
+	scale := (radius * 3 / 2) / width
+	if s := radius / 4; s < scale {
+		scale = s
+	}
+	if scale < 1 {
+		scale = 1
+	}
+
+	x := cx - width*scale/2
+	y := cy - 5*scale/2
+	for _, r := range text {
+		glyph := digits[r-'0']
+		for gy, line := range glyph {
+			for gx, px := range line {
+				if px == '#' {
+					fillRect(img, x+gx*scale, y+gy*scale, x+(gx+1)*scale, y+(gy+1)*scale, c)
+				}
+			}
+		}
+		x += 4 * scale
+	}
+}
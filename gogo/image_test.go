@@ -0,0 +1,184 @@
+package gogo
+
+import (
+	"bytes"
+	"fmt"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBoardToPNG(t *testing.T) {
+	tests := []struct {
+		size       int
+		inputs     []string
+		opts       ImageOptions
+		expectSize int
+		// pixel positions expected to be a given palette colour
+		expectPixels map[[2]int]uint8
+	}{
+		{
+			size:       9,
+			expectSize: DefaultImageSize,
+			expectPixels: map[[2]int]uint8{
+				{0, 0}: woodIdx,
+				// bottom left corner of the grid
+				{40, 360}: lineIdx,
+			},
+		},
+		{
+			size:       9,
+			inputs:     []string{"A1", "B1"},
+			opts:       ImageOptions{Size: 100},
+			expectSize: 100,
+			expectPixels: map[[2]int]uint8{
+				{10, 90}: lineIdx,
+				{20, 90}: whiteIdx,
+			},
+		},
+		{
+			size:       4,
+			inputs:     []string{"A1", "A4"},
+			opts:       ImageOptions{Size: 200, MoveNumbers: true},
+			expectSize: 200,
+			expectPixels: map[[2]int]uint8{
+				// middle of the "1" on black's stone is drawn in white
+				{40, 160}: whiteIdx,
+				// middle of the "2" on white's stone is drawn in black
+				{40, 40}: lineIdx,
+			},
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v inputs %v", i, strings.Join(tt.inputs, "_")), func(t *testing.T) {
+			board, err := NewBoard(tt.size)
+			require.NoError(t, err)
+
+			for _, in := range tt.inputs {
+				_, err := board.Play(in)
+				require.NoError(t, err)
+			}
+
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, board.EncodePNG(buf, tt.opts))
+
+			img, err := png.Decode(buf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectSize, img.Bounds().Dx())
+			assert.Equal(t, tt.expectSize, img.Bounds().Dy())
+
+			for px, c := range tt.expectPixels {
+				assert.Equal(t, boardPalette[c], boardPalette.Convert(img.At(px[0], px[1])), "pixel %v", px)
+			}
+		})
+	}
+}
+
+func TestRenderGameReplayToGIF(t *testing.T) {
+	tests := []struct {
+		size         int
+		inputs       []string
+		opts         ImageOptions
+		expectFrames int
+		expectDelay  int
+	}{
+		{size: 9, expectFrames: 1, expectDelay: DefaultFrameDelay},
+		{
+			size:         9,
+			inputs:       []string{"A1", "B1", "C1"},
+			opts:         ImageOptions{Size: 90, Delay: 50},
+			expectFrames: 4,
+			expectDelay:  50,
+		},
+		// includes a capture, so later frames must have the captured
+		// stone removed
+		{
+			size:         4,
+			inputs:       []string{"B1", "B2", "A2", "A3", "C2", "C3", "B3"},
+			opts:         ImageOptions{Size: 50, MoveNumbers: true},
+			expectFrames: 8,
+			expectDelay:  DefaultFrameDelay,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v inputs %v", i, strings.Join(tt.inputs, "_")), func(t *testing.T) {
+			board, err := NewBoard(tt.size)
+			require.NoError(t, err)
+
+			for _, in := range tt.inputs {
+				_, err := board.Play(in)
+				require.NoError(t, err)
+			}
+
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, board.EncodeGIF(buf, tt.opts))
+
+			anim, err := gif.DecodeAll(buf)
+			require.NoError(t, err)
+			require.Len(t, anim.Image, tt.expectFrames)
+			for _, d := range anim.Delay {
+				assert.Equal(t, tt.expectDelay, d)
+			}
+
+			last := anim.Image[len(anim.Image)-1]
+			expect := board.Image(tt.opts)
+			assert.Equal(t, expect.Pix, last.Pix, "last frame should match the current position")
+		})
+	}
+}
+
+func TestReplayRemovesCapturedStones(t *testing.T) {
+	board, err := NewBoard(4)
+	require.NoError(t, err)
+
+	for _, in := range []string{"B1", "B2", "A2", "A3", "C2", "C3", "B3"} {
+		_, err := board.Play(in)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		moves       int
+		expectB2    rune
+		expectBlack int
+	}{
+		{moves: 0, expectB2: emptySpace},
+		{moves: 2, expectB2: whitePiece},
+		{moves: 6, expectB2: whitePiece},
+		{moves: 7, expectB2: emptySpace, expectBlack: 1},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v moves %v", i, tt.moves), func(t *testing.T) {
+			replay := board.replay(tt.moves)
+			assert.Equal(t, tt.moves, replay.MoveNumber())
+			assert.Equal(t, string(tt.expectB2), string(replay.board[replay.coordsToIdx(2, 2)]))
+
+			gotBlack, _ := replay.Prisoners()
+			assert.Equal(t, tt.expectBlack, gotBlack)
+		})
+	}
+}
+
+func TestRenderRectBoardToPNG(t *testing.T) {
+	board, err := NewRectBoard(9, 4)
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, board.EncodePNG(buf, ImageOptions{Size: 100}))
+
+	img, err := png.Decode(buf)
+	require.NoError(t, err)
+	// the long side gets the full size, the short side only as many
+	// cells as it needs
+	assert.Equal(t, 100, img.Bounds().Dx())
+	assert.Equal(t, 50, img.Bounds().Dy())
+}
@@ -0,0 +1,335 @@
+package gogo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// jsonEmpty is how an empty point is written in the JSON schema.
+const jsonEmpty rune = '.'
+
+// boardJSON ...
+type boardJSON struct {
+	Code   string `json:"code"`
+	Size   int    `json:"size,omitempty"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	settings
+	Points     []string       `json:"points"`
+	ToMove     string         `json:"toMove"`
+	Prisoners  map[string]int `json:"prisoners"`
+	Ko         *string        `json:"ko"`
+	MoveNumber int            `json:"moveNumber"`
+}
+
+// MarshalJSON encodes the current position of the board, using this
+// schema:
+//
+//	{
+//	  "code": "KMYC",
+//	  "size": 4,
+//	  "width": 4,
+//	  "height": 4,
+//	  "topology": "torus",
+//	  "players": 3,
+//	  "variant": "japanese",
+//	  "komi": 0,
+//	  "handicap": 2,
+//	  "seats": {"black": {"id": "u1", "name": "Sai", "rank": "9p"}},
+//	  "points": ["....", "....", ".W..", "B..."],
+//	  "toMove": "black",
+//	  "prisoners": {"black": 0, "white": 0},
+//	  "ko": "1,1",
+//	  "moveNumber": 2
+//	}
+//
+// "size" is only set for square boards, "topology" only for boards that
+// aren't a Plane, "players" only for games with more than two players,
+// "variant", the name of the rules, only for games that aren't played by
+// DefaultRules, "ruleset" only for a Ruleset that isn't one of the
+// presets, with its fields, "komi" only when it was set WithKomi, and
+// "handicap" only for handicap games. "seats" has the Player at each
+// colour that's been taken, and is left out if none are. "points" has
+// one string per row, from the top row ( row "height" ) down to row 1,
+// and one character per column from A. '.' is an empty point, 'B' a
+// black stone and 'W' a white stone, with 'R' and 'G' for red and green.
+// "ko" is the point that can't be played this turn because of ko, or
+// null, in XY notation like the points in a Result. "toMove" is the
+// player whose turn it is, "black", "white", "red" or "green".
+// "prisoners" has an entry for every player.
+//
+// The move history isn't included.
+func (b Board) MarshalJSON() ([]byte, error) {
+	if b.board == nil {
+		return nil, fmt.Errorf("can't marshal an invalid board")
+	}
+
+	out := boardJSON{
+		Code:       b.code,
+		Width:      b.width,
+		Height:     b.height,
+		Points:     make([]string, 0, b.height),
+		ToMove:     b.currentPlayer,
+		Prisoners:  map[string]int{},
+		MoveNumber: b.MoveNumber(),
+		settings:   b.settings(),
+	}
+	for _, p := range b.players() {
+		out.Prisoners[colourToPlayer(p)] = b.prisoners[p]
+	}
+
+	if b.width == b.height {
+		out.Size = b.width
+	}
+
+	for i := b.height; i >= 1; i-- {
+		row := strings.Builder{}
+		for j := 1; j <= b.width; j++ {
+			p := b.board[b.coordsToIdx(i, j)]
+			if p == emptySpace {
+				p = jsonEmpty
+			}
+			row.WriteRune(p)
+		}
+		out.Points = append(out.Points, row.String())
+	}
+
+	if b.ko >= 0 {
+		ko := b.idxToPoint(b.ko).XY()
+		out.Ko = &ko
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON restores a board from a position written by MarshalJSON.
+// The restored board has no history before that position, so Undo, At
+// and EncodeGIF only cover moves played after it was loaded.
+func (b *Board) UnmarshalJSON(data []byte) error {
+	var in boardJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	if in.Width == 0 && in.Height == 0 {
+		in.Width, in.Height = in.Size, in.Size
+	}
+
+	opts, err := in.settings.options()
+	if err != nil {
+		return err
+	}
+
+	out, err := NewRectBoard(in.Width, in.Height, opts...)
+	if err != nil {
+		return err
+	}
+	out.code = in.Code
+	DefaultCodes.use(out.code)
+
+	if len(in.Points) != in.Height {
+		return fmt.Errorf("expected %v rows of points, got %v", in.Height, len(in.Points))
+	}
+	for r, row := range in.Points {
+		points := []rune(row)
+		if len(points) != in.Width {
+			return fmt.Errorf("expected %v points in row %v, got %v", in.Width, in.Height-r, len(points))
+		}
+		for c, p := range points {
+			if p == jsonEmpty {
+				p = emptySpace
+			} else if !out.isPlaying(colourToPlayer(p)) {
+				return fmt.Errorf("unknown point %q in row %v", string(p), in.Height-r)
+			}
+			out.board[out.coordsToIdx(in.Height-r, c+1)] = p
+		}
+	}
+
+	if !out.isPlaying(in.ToMove) {
+		return fmt.Errorf("unknown player to move %q", in.ToMove)
+	}
+	out.nextPiece = playerToColour(in.ToMove)
+	out.currentPlayer = in.ToMove
+
+	for _, p := range out.players() {
+		out.prisoners[p] = in.Prisoners[colourToPlayer(p)]
+	}
+
+	if in.Ko != nil {
+		p, err := ParseXY(*in.Ko)
+		if err != nil {
+			return fmt.Errorf("invalid ko point: %w", err)
+		}
+		idx, err := out.pointToIdx(p)
+		if err != nil {
+			return fmt.Errorf("invalid ko point: %w", err)
+		}
+		if out.board[idx] != emptySpace {
+			return fmt.Errorf("invalid ko point %q, it's occupied", *in.Ko)
+		}
+		out.ko = idx
+	}
+
+	if in.MoveNumber < 0 {
+		return fmt.Errorf("invalid move number %v", in.MoveNumber)
+	}
+
+	start := &position{
+		board:      make([]rune, len(out.board)),
+		nextPiece:  out.nextPiece,
+		prisoners:  map[rune]int{},
+		ko:         out.ko,
+		moveNumber: in.MoveNumber,
+	}
+	copy(start.board, out.board)
+	for p, n := range out.prisoners {
+		start.prisoners[p] = n
+	}
+	out.start = start
+
+	if b.rand != nil {
+		out.rand = b.rand
+	} else {
+		out.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	out.logger = b.logger
+
+	*b = out
+	return nil
+}
+
+// resultJSON ...
+type resultJSON struct {
+	Pieces     map[string]int `json:"pieces"`
+	MoveNumber int            `json:"moveNumber"`
+	Player     string         `json:"player"`
+	Captured   []string       `json:"captured"`
+	Prisoners  map[string]int `json:"prisoners"`
+	Ko         *string        `json:"ko"`
+	Atari      []groupJSON    `json:"atari"`
+	GameOver   bool           `json:"gameOver"`
+	Winner     string         `json:"winner,omitempty"`
+	Result     *GameResult    `json:"result,omitempty"`
+}
+
+// groupJSON ...
+type groupJSON struct {
+	Player string   `json:"player"`
+	Points []string `json:"points"`
+}
+
+// MarshalJSON encodes the result, using this schema:
+//
+//	{
+//	  "pieces": {"black": 1, "white": 0},
+//	  "moveNumber": 3,
+//	  "player": "black",
+//	  "captured": ["0,1"],
+//	  "prisoners": {"black": 1, "white": 0},
+//	  "ko": "0,1",
+//	  "atari": [{"player": "black", "points": ["1,1"]}],
+//	  "gameOver": true,
+//	  "winner": "black",
+//	  "result": {"winner": "black", "reason": "rules"}
+//	}
+//
+// "pieces" is how many stones each player has on the board after the
+// move, and "prisoners" how many they've captured so far. "player" made
+// the move, which captured the stones at "captured". "ko" is the point
+// the next player can't play because of ko, or null, and "atari" has
+// every group left with one liberty. Points are written in XY notation,
+// so they work for any board. "winner" is only set when the rules pick
+// a winner, and "result", the GameResult, only when the move ended the
+// game.
+func (r Result) MarshalJSON() ([]byte, error) {
+	out := resultJSON{
+		Pieces:     map[string]int{blackPlayer: 0, whitePlayer: 0},
+		MoveNumber: r.moveNumber,
+		Player:     r.player,
+		Captured:   []string{},
+		Prisoners:  map[string]int{blackPlayer: 0, whitePlayer: 0},
+		Atari:      []groupJSON{},
+		GameOver:   r.game != nil,
+		Winner:     r.winner,
+		Result:     r.game,
+	}
+	for p, n := range r.pieces {
+		out.Pieces[p] = n
+	}
+	for p, n := range r.prisoners {
+		out.Prisoners[p] = n
+	}
+	for _, p := range r.captured {
+		out.Captured = append(out.Captured, p.XY())
+	}
+	if r.ko != nil {
+		ko := r.ko.XY()
+		out.Ko = &ko
+	}
+	for _, g := range r.atari {
+		group := groupJSON{Player: g.Player, Points: []string{}}
+		for _, p := range g.Points {
+			group.Points = append(group.Points, p.XY())
+		}
+		out.Atari = append(out.Atari, group)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON restores a result written by MarshalJSON.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var in resultJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	out := Result{
+		pieces:     in.Pieces,
+		winner:     in.Winner,
+		game:       in.Result,
+		moveNumber: in.MoveNumber,
+		player:     in.Player,
+		prisoners:  in.Prisoners,
+	}
+	if in.GameOver && out.game == nil {
+		return fmt.Errorf("result of a move that ended the game is missing")
+	}
+
+	var err error
+	if out.captured, err = parseXYs(in.Captured); err != nil {
+		return fmt.Errorf("invalid captured point: %w", err)
+	}
+	if in.Ko != nil {
+		ko, err := ParseXY(*in.Ko)
+		if err != nil {
+			return fmt.Errorf("invalid ko point: %w", err)
+		}
+		out.ko = &ko
+	}
+	for _, g := range in.Atari {
+		points, err := parseXYs(g.Points)
+		if err != nil {
+			return fmt.Errorf("invalid point in atari: %w", err)
+		}
+		out.atari = append(out.atari, Group{Player: g.Player, Points: points})
+	}
+
+	*r = out
+	return nil
+}
+
+// parseXYs ...
+func parseXYs(in []string) ([]Point, error) {
+	out := []Point{}
+	for _, s := range in {
+		p, err := ParseXY(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
@@ -0,0 +1,201 @@
+package gogo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalBoardJSON(t *testing.T) {
+	tests := []struct {
+		size    int
+		actions []string
+		expect  string
+	}{
+		{
+			size:   4,
+			expect: `{"code":"TEST","size":4,"width":4,"height":4,"points":["....","....","....","...."],"toMove":"black","prisoners":{"black":0,"white":0},"ko":null,"moveNumber":0}`,
+		},
+		{
+			size:    4,
+			actions: []string{"A1", "B2", "pass"},
+			expect:  `{"code":"TEST","size":4,"width":4,"height":4,"points":["....","....",".W..","B..."],"toMove":"white","prisoners":{"black":0,"white":0},"ko":null,"moveNumber":3}`,
+		},
+		{
+			size:    4,
+			actions: koSetup,
+			expect:  `{"code":"TEST","size":4,"width":4,"height":4,"points":["...B",".BW.","B.BW",".BW."],"toMove":"white","prisoners":{"black":1,"white":0},"ko":"1,1","moveNumber":9}`,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewBoard(tt.size)
+			require.NoError(t, err)
+			board.code = "TEST"
+			require.NoError(t, play(&board, tt.actions))
+
+			got, err := json.Marshal(board)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expect, string(got))
+
+			var loaded Board
+			require.NoError(t, json.Unmarshal(got, &loaded))
+			assert.Equal(t, board.String(), loaded.String())
+			assert.Equal(t, board.Code(), loaded.Code())
+			assert.Equal(t, board.CurrentPlayer(), loaded.CurrentPlayer())
+			assert.Equal(t, board.MoveNumber(), loaded.MoveNumber())
+			assert.Equal(t, board.ko, loaded.ko)
+
+			expectBlack, expectWhite := board.Prisoners()
+			gotBlack, gotWhite := loaded.Prisoners()
+			assert.Equal(t, expectBlack, gotBlack)
+			assert.Equal(t, expectWhite, gotWhite)
+
+			again, err := json.Marshal(loaded)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(got), string(again))
+		})
+	}
+}
+
+func TestUnmarshalBoardJSONErrors(t *testing.T) {
+	tests := []struct {
+		input string
+	}{
+		{input: `[]`},
+		{input: `{"size":3,"points":["...","...","..."],"toMove":"black"}`},
+		{input: `{"size":4,"points":["....","....","...."],"toMove":"black"}`},
+		{input: `{"size":4,"points":["....","....","...","...."],"toMove":"black"}`},
+		{input: `{"size":4,"points":["....","....","..R.","...."],"toMove":"black"}`},
+		{input: `{"size":4,"points":["....","....","....","...."],"toMove":"red"}`},
+		{input: `{"size":4,"points":["....","....","....","...."],"toMove":"black","ko":"4,4"}`},
+		{input: `{"size":4,"points":["....","....","....","B..."],"toMove":"black","ko":"0,0"}`},
+		{input: `{"size":4,"points":["....","....","....","...."],"toMove":"black","ko":"B2"}`},
+		{input: `{"size":4,"points":["....","....","....","...."],"toMove":"black","moveNumber":-1}`},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			var b Board
+			assert.Error(t, json.Unmarshal([]byte(tt.input), &b))
+		})
+	}
+}
+
+func TestPlayOnBoardLoadedFromJSON(t *testing.T) {
+	var board Board
+	input := `{"code":"TEST","size":4,"width":4,"height":4,"points":["...B",".BW.","B.BW",".BW."],"toMove":"white","prisoners":{"black":1,"white":0},"ko":"1,1","moveNumber":9}`
+	require.NoError(t, json.Unmarshal([]byte(input), &board))
+
+	// the ko is still in effect
+	_, err := board.Play("B2")
+	require.Error(t, err)
+
+	require.NoError(t, play(&board, []string{"A4", "A3", "B2"}))
+	assert.Equal(t, 12, board.MoveNumber())
+	assert.Equal(t, `4 W X X B
+3 B B W X
+2 B W X W
+1 X B W X
+  A B C D`, board.String())
+
+	// history only goes back as far as the loaded position
+	start, err := board.At(9)
+	require.NoError(t, err)
+	assert.Equal(t, `4 X X X B
+3 X B W X
+2 B X B W
+1 X B W X
+  A B C D`, start.String())
+
+	require.NoError(t, board.Undo())
+	require.NoError(t, board.Undo())
+	require.NoError(t, board.Undo())
+	assert.Error(t, board.Undo())
+	assert.Equal(t, start.String(), board.String())
+
+	// and the starting position survives a trip through a store
+	store := NewMemoryStore()
+	require.NoError(t, play(&board, []string{"A4", "A3"}))
+	require.NoError(t, store.Save(&board))
+	loaded, err := store.Load("TEST")
+	require.NoError(t, err)
+	assert.Equal(t, board.String(), loaded.String())
+	assert.Equal(t, board.MoveNumber(), loaded.MoveNumber())
+}
+
+func TestResultJSON(t *testing.T) {
+	ko := Point{X: 0, Y: 1}
+	tests := []struct {
+		result Result
+		expect string
+	}{
+		{expect: `{"pieces":{"black":0,"white":0},"moveNumber":0,"player":"","captured":[],"prisoners":{"black":0,"white":0},"ko":null,"atari":[],"gameOver":false}`},
+		{
+			result: Result{pieces: map[string]int{blackPlayer: 4, whitePlayer: 2}},
+			expect: `{"pieces":{"black":4,"white":2},"moveNumber":0,"player":"","captured":[],"prisoners":{"black":0,"white":0},"ko":null,"atari":[],"gameOver":false}`,
+		},
+		{
+			result: Result{pieces: map[string]int{blackPlayer: 4, whitePlayer: 2, redPlayer: 3}},
+			expect: `{"pieces":{"black":4,"white":2,"red":3},"moveNumber":0,"player":"","captured":[],"prisoners":{"black":0,"white":0},"ko":null,"atari":[],"gameOver":false}`,
+		},
+		{
+			result: Result{
+				pieces:     map[string]int{blackPlayer: 3, whitePlayer: 1},
+				moveNumber: 7,
+				player:     blackPlayer,
+				captured:   []Point{{X: 0, Y: 1}},
+				prisoners:  map[string]int{blackPlayer: 1, whitePlayer: 0},
+				ko:         &ko,
+				atari:      []Group{{Player: blackPlayer, Points: []Point{{X: 1, Y: 1}}}},
+				winner:     blackPlayer,
+				game:       &GameResult{Winner: blackPlayer, Reason: ReasonRules},
+			},
+			expect: `{
+				"pieces":{"black":3,"white":1},
+				"moveNumber":7,
+				"player":"black",
+				"captured":["0,1"],
+				"prisoners":{"black":1,"white":0},
+				"ko":"0,1",
+				"atari":[{"player":"black","points":["1,1"]}],
+				"gameOver":true,
+				"winner":"black",
+				"result":{"winner":"black","reason":"rules"}
+			}`,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			got, err := json.Marshal(tt.result)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expect, string(got))
+
+			var loaded Result
+			require.NoError(t, json.Unmarshal(got, &loaded))
+			for _, p := range []string{blackPlayer, whitePlayer, redPlayer} {
+				assert.Equal(t, tt.result.PiecesOf(p), loaded.PiecesOf(p), p)
+				assert.Equal(t, tt.result.PrisonersOf(p), loaded.PrisonersOf(p), p)
+			}
+			assert.Equal(t, tt.result.MoveNumber(), loaded.MoveNumber())
+			assert.Equal(t, tt.result.Player(), loaded.Player())
+			assert.ElementsMatch(t, tt.result.Captured(), loaded.Captured())
+			assert.ElementsMatch(t, tt.result.Atari(), loaded.Atari())
+			assert.Equal(t, tt.result.GameOver(), loaded.GameOver())
+			assert.Equal(t, tt.result.Winner(), loaded.Winner())
+			expectKo, expectOk := tt.result.Ko()
+			gotKo, gotOk := loaded.Ko()
+			assert.Equal(t, expectOk, gotOk)
+			assert.Equal(t, expectKo, gotKo)
+		})
+	}
+}
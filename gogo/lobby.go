@@ -0,0 +1,507 @@
+package gogo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrOfferNotFound is returned by a Lobby when there's no open listing,
+// seek or challenge with the requested ID, including ones that have
+// expired.
+var ErrOfferNotFound = errors.New("offer not found")
+
+// DefaultOfferTTL is how long offers stay open in a Lobby, unless it's
+// set WithOfferTTL.
+const DefaultOfferTTL = 10 * time.Minute
+
+// TimeControl is how long each player has to play. Main is the time each
+// player has for the whole game, after which they get Periods byo-yomi
+// periods of Period each. The zero TimeControl has no time limit.
+type TimeControl struct {
+	Main    time.Duration `json:"main,omitempty"`
+	Periods int           `json:"periods,omitempty"`
+	Period  time.Duration `json:"period,omitempty"`
+}
+
+// String formats the time control like "10m0s+5x30s", or "none".
+func (t TimeControl) String() string {
+	if t == (TimeControl{}) {
+		return "none"
+	}
+	out := t.Main.String()
+	if t.Periods > 0 {
+		out += fmt.Sprintf("+%vx%v", t.Periods, t.Period)
+	}
+	return out
+}
+
+// GameSettings are what a game offered in the Lobby is played with.
+type GameSettings struct {
+	Size int
+	// TimeControl is only used to match players who want the same
+	// one. The game itself isn't timed, so keeping each player's time
+	// is up to whatever runs it.
+	TimeControl TimeControl
+	// Rules is what the game is played by, or nil for DefaultRules.
+	Rules Rules
+}
+
+// matches returns true if s and o are the same settings. Rules can't
+// always be compared with ==, so they're compared by name, unless both
+// are a Ruleset.
+func (s GameSettings) matches(o GameSettings) bool {
+	if s.Size != o.Size || s.TimeControl != o.TimeControl {
+		return false
+	}
+	a, b := s.rules(), o.rules()
+	if ra, ok := a.(Ruleset); ok {
+		rb, ok := b.(Ruleset)
+		return ok && ra == rb
+	}
+	return a.Name() == b.Name()
+}
+
+// rules ...
+func (s GameSettings) rules() Rules {
+	if s.Rules == nil {
+		return DefaultRules
+	}
+	return s.Rules
+}
+
+// newBoard ...
+func (s GameSettings) newBoard(opts ...Option) (Board, error) {
+	if s.Rules != nil {
+		opts = append(opts, WithRules(s.Rules))
+	}
+	return NewBoard(s.Size, opts...)
+}
+
+// Listing is an open game anyone in the rank range can join.
+type Listing struct {
+	ID       string
+	Host     Player
+	Settings GameSettings
+	// MinRank and MaxRank are the weakest and strongest ranks that can
+	// join; zero means there's no limit that way.
+	MinRank Rank
+	MaxRank Rank
+	Expires time.Time
+}
+
+// allows ...
+func (l Listing) allows(p Player) error {
+	rank, err := ParseRank(p.Rank)
+	if err != nil {
+		return err
+	}
+	if l.MinRank == 0 && l.MaxRank == 0 {
+		return nil
+	}
+	if rank == 0 {
+		return fmt.Errorf("listing %v is only for ranked players", l.ID)
+	}
+	if (l.MinRank != 0 && rank < l.MinRank) || (l.MaxRank != 0 && rank > l.MaxRank) {
+		return fmt.Errorf("listing %v is for %v to %v, not %v", l.ID, l.MinRank, l.MaxRank, rank)
+	}
+	return nil
+}
+
+// Seek is a player waiting to be paired automatically with anyone who
+// wants the same settings and whose rating is within both players'
+// bands.
+type Seek struct {
+	ID       string
+	Player   Player
+	Settings GameSettings
+	Rating   float64
+	// Band is how far away the opponent's rating can be.
+	Band    float64
+	Expires time.Time
+}
+
+// Challenge is a game offered to one player in particular.
+type Challenge struct {
+	ID       string
+	From     Player
+	To       Player
+	Settings GameSettings
+	Expires  time.Time
+}
+
+// Lobby is where players find games: open listings anyone in range can
+// join, seeks that are paired automatically by rating, and challenges to
+// a particular player. Every offer expires after a while. Games that are
+// made are created with NewBoard, with colours decided by nigiri, and
+// saved to the lobby's Store. It's safe for concurrent use.
+type Lobby struct {
+	mu         sync.Mutex
+	store      Store
+	codes      *CodeGenerator
+	ttl        time.Duration
+	now        func() time.Time
+	lastID     int
+	listings   map[string]Listing
+	seeks      map[string]Seek
+	challenges map[string]Challenge
+}
+
+// LobbyOption configures a Lobby when it's created.
+type LobbyOption func(*Lobby) error
+
+// WithOfferTTL sets how long offers stay open.
+func WithOfferTTL(ttl time.Duration) LobbyOption {
+	return func(l *Lobby) error {
+		if ttl <= 0 {
+			return fmt.Errorf("offer TTL of %v isn't positive", ttl)
+		}
+		l.ttl = ttl
+		return nil
+	}
+}
+
+// WithGameCodes gives every game the lobby makes a code from g, so no
+// two games share one. The codes of the games already in the lobby's
+// store are reserved when it's created.
+func WithGameCodes(g *CodeGenerator) LobbyOption {
+	return func(l *Lobby) error {
+		l.codes = g
+		return nil
+	}
+}
+
+// NewLobby creates an empty lobby that saves the games it makes to
+// store.
+func NewLobby(store Store, opts ...LobbyOption) (*Lobby, error) {
+	if store == nil {
+		return nil, fmt.Errorf("a lobby needs a store for its games")
+	}
+
+	l := &Lobby{
+		store:      store,
+		ttl:        DefaultOfferTTL,
+		now:        time.Now,
+		listings:   map[string]Listing{},
+		seeks:      map[string]Seek{},
+		challenges: map[string]Challenge{},
+	}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	if l.codes != nil {
+		if err := l.codes.ReserveStored(store); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// List opens a listing for host, returning it. minRank and maxRank limit
+// who can join; use zero for no limit.
+func (l *Lobby) List(host Player, settings GameSettings, minRank, maxRank Rank) (Listing, error) {
+	if err := checkOffer(host, settings); err != nil {
+		return Listing{}, err
+	}
+	if minRank != 0 && maxRank != 0 && minRank > maxRank {
+		return Listing{}, fmt.Errorf("rank range %v to %v is empty", minRank, maxRank)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	listing := Listing{
+		ID:       l.nextID(),
+		Host:     host,
+		Settings: settings,
+		MinRank:  minRank,
+		MaxRank:  maxRank,
+		Expires:  l.now().Add(l.ttl),
+	}
+	l.listings[listing.ID] = listing
+	return listing, nil
+}
+
+// Listings returns the open listings, oldest first.
+func (l *Lobby) Listings() []Listing {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	out := make([]Listing, 0, len(l.listings))
+	for _, listing := range l.listings {
+		out = append(out, listing)
+	}
+	sort.Slice(out, func(i, j int) bool { return idLess(out[i].ID, out[j].ID) })
+	return out
+}
+
+// JoinListing joins p to an open listing, closing it and starting the
+// game.
+func (l *Lobby) JoinListing(id string, p Player) (Board, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	listing, ok := l.listings[id]
+	if !ok {
+		return Board{}, fmt.Errorf("can't join listing %q: %w", id, ErrOfferNotFound)
+	}
+	if p.ID == listing.Host.ID {
+		return Board{}, fmt.Errorf("can't join your own listing")
+	}
+	if err := listing.allows(p); err != nil {
+		return Board{}, err
+	}
+
+	b, err := l.start(listing.Settings, listing.Host, p)
+	if err != nil {
+		return Board{}, err
+	}
+	delete(l.listings, id)
+	return b, nil
+}
+
+// Seek pairs p with the longest waiting seek that wants the same
+// settings, where each player's rating is within the other's band. If
+// there's a match, the game is started and returned; otherwise the seek
+// waits, and its ID is returned so it can be cancelled.
+func (l *Lobby) Seek(p Player, settings GameSettings, rating, band float64) (Board, string, error) {
+	if err := checkOffer(p, settings); err != nil {
+		return Board{}, "", err
+	}
+	if band < 0 {
+		return Board{}, "", fmt.Errorf("rating band of %v is negative", band)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	waiting := make([]Seek, 0, len(l.seeks))
+	for _, s := range l.seeks {
+		waiting = append(waiting, s)
+	}
+	sort.Slice(waiting, func(i, j int) bool { return idLess(waiting[i].ID, waiting[j].ID) })
+
+	for _, s := range waiting {
+		if s.Player.ID == p.ID || !s.Settings.matches(settings) {
+			continue
+		}
+		if diff := math.Abs(s.Rating - rating); diff > s.Band || diff > band {
+			continue
+		}
+
+		b, err := l.start(settings, s.Player, p)
+		if err != nil {
+			return Board{}, "", err
+		}
+		delete(l.seeks, s.ID)
+		return b, "", nil
+	}
+
+	s := Seek{
+		ID:       l.nextID(),
+		Player:   p,
+		Settings: settings,
+		Rating:   rating,
+		Band:     band,
+		Expires:  l.now().Add(l.ttl),
+	}
+	l.seeks[s.ID] = s
+	return Board{}, s.ID, nil
+}
+
+// Challenge offers a game to another player, returning the challenge
+// for them to accept or decline.
+func (l *Lobby) Challenge(from, to Player, settings GameSettings) (Challenge, error) {
+	if err := checkOffer(from, settings); err != nil {
+		return Challenge{}, err
+	}
+	if to.ID == "" || to.ID == from.ID {
+		return Challenge{}, fmt.Errorf("can't challenge %q", to.ID)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	c := Challenge{
+		ID:       l.nextID(),
+		From:     from,
+		To:       to,
+		Settings: settings,
+		Expires:  l.now().Add(l.ttl),
+	}
+	l.challenges[c.ID] = c
+	return c, nil
+}
+
+// Challenges returns the open challenges to or from the player with the
+// given ID, oldest first.
+func (l *Lobby) Challenges(id string) []Challenge {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	out := []Challenge{}
+	for _, c := range l.challenges {
+		if c.From.ID == id || c.To.ID == id {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return idLess(out[i].ID, out[j].ID) })
+	return out
+}
+
+// Accept accepts a challenge to the player with the given ID, starting
+// the game.
+func (l *Lobby) Accept(challengeID, playerID string) (Board, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	c, ok := l.challenges[challengeID]
+	if !ok || c.To.ID != playerID {
+		return Board{}, fmt.Errorf("can't accept challenge %q: %w", challengeID, ErrOfferNotFound)
+	}
+
+	b, err := l.start(c.Settings, c.From, c.To)
+	if err != nil {
+		return Board{}, err
+	}
+	delete(l.challenges, challengeID)
+	return b, nil
+}
+
+// Decline turns down a challenge to the player with the given ID.
+func (l *Lobby) Decline(challengeID, playerID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	c, ok := l.challenges[challengeID]
+	if !ok || c.To.ID != playerID {
+		return fmt.Errorf("can't decline challenge %q: %w", challengeID, ErrOfferNotFound)
+	}
+	delete(l.challenges, challengeID)
+	return nil
+}
+
+// Cancel withdraws a listing, seek or challenge made by the player with
+// the given ID.
+func (l *Lobby) Cancel(offerID, playerID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+
+	if listing, ok := l.listings[offerID]; ok && listing.Host.ID == playerID {
+		delete(l.listings, offerID)
+		return nil
+	}
+	if s, ok := l.seeks[offerID]; ok && s.Player.ID == playerID {
+		delete(l.seeks, offerID)
+		return nil
+	}
+	if c, ok := l.challenges[offerID]; ok && c.From.ID == playerID {
+		delete(l.challenges, offerID)
+		return nil
+	}
+	return fmt.Errorf("can't cancel %q: %w", offerID, ErrOfferNotFound)
+}
+
+// Expire removes every offer that's past its expiry time. Offers are
+// also expired whenever the lobby is used, so this is only needed to
+// free them up sooner.
+func (l *Lobby) Expire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire()
+}
+
+// expire ...
+func (l *Lobby) expire() {
+	now := l.now()
+	for id, listing := range l.listings {
+		if !now.Before(listing.Expires) {
+			delete(l.listings, id)
+		}
+	}
+	for id, s := range l.seeks {
+		if !now.Before(s.Expires) {
+			delete(l.seeks, id)
+		}
+	}
+	for id, c := range l.challenges {
+		if !now.Before(c.Expires) {
+			delete(l.challenges, id)
+		}
+	}
+}
+
+// start creates a game between two players, decides who plays black by
+// nigiri, and saves it. The settings' TimeControl has already done its
+// job by matching the players, so it isn't part of the game.
+func (l *Lobby) start(settings GameSettings, p1, p2 Player) (_ Board, err error) {
+	b, err := settings.newBoard()
+	if err != nil {
+		return Board{}, err
+	}
+	if l.codes != nil {
+		if b.code, err = l.codes.New(); err != nil {
+			return Board{}, err
+		}
+		DefaultCodes.use(b.code)
+	}
+	// the code is free for another game if this one doesn't start
+	defer func() {
+		if err != nil && b.code != "" {
+			if l.codes != nil {
+				l.codes.Release(b.code)
+			}
+			DefaultCodes.Release(b.code)
+		}
+	}()
+
+	if err := b.Nigiri(p1, p2); err != nil {
+		return Board{}, err
+	}
+	if err := l.store.Save(&b); err != nil {
+		return Board{}, err
+	}
+	return b, nil
+}
+
+// nextID ...
+func (l *Lobby) nextID() string {
+	l.lastID++
+	return strconv.Itoa(l.lastID)
+}
+
+// idLess orders IDs from nextID by when they were made.
+func idLess(a, b string) bool {
+	x, _ := strconv.Atoi(a)
+	y, _ := strconv.Atoi(b)
+	return x < y
+}
+
+// checkOffer checks an offer can be made.
+func checkOffer(p Player, settings GameSettings) error {
+	if p.ID == "" {
+		return fmt.Errorf("player has no ID")
+	}
+	if settings.Size < MinBoardSize || settings.Size > MaxBoardSize {
+		return fmt.Errorf("%w, %v isn't between %v and %v", ErrBoardSize, settings.Size, MinBoardSize, MaxBoardSize)
+	}
+	if _, err := ParseRank(p.Rank); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package gogo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLobby returns a lobby with a clock that only moves when told to.
+func testLobby(t *testing.T, opts ...LobbyOption) (*Lobby, *MemoryStore, func(time.Duration)) {
+	store := NewMemoryStore()
+	l, err := NewLobby(store, opts...)
+	require.NoError(t, err)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return l, store, func(d time.Duration) { now = now.Add(d) }
+}
+
+// checkGame checks a game was started between two players and saved.
+func checkGame(t *testing.T, store Store, b Board, p1, p2 Player) {
+	black, ok := b.Seat(blackPlayer)
+	require.True(t, ok)
+	white, ok := b.Seat(whitePlayer)
+	require.True(t, ok)
+	assert.ElementsMatch(t, []Player{p1, p2}, []Player{black, white})
+
+	loaded, err := store.Load(b.Code())
+	require.NoError(t, err)
+	seat, _ := loaded.Seat(blackPlayer)
+	assert.Equal(t, black, seat)
+}
+
+func TestLobbyListings(t *testing.T) {
+	nineByNine := GameSettings{Size: 9, TimeControl: TimeControl{Main: 10 * time.Minute, Periods: 3, Period: 30 * time.Second}}
+
+	tests := []struct {
+		minRank, maxRank Rank
+		joiner           Player
+		expectErr        bool
+	}{
+		{joiner: carol},
+		{minRank: Kyu(5), maxRank: Dan(2), joiner: alice},
+		{minRank: Kyu(5), maxRank: Dan(2), joiner: bob},
+		{minRank: Kyu(2), joiner: alice, expectErr: true},
+		{maxRank: Kyu(5), joiner: bob, expectErr: true},
+		{minRank: Kyu(5), joiner: carol, expectErr: true},
+		{joiner: Player{ID: "dave", Rank: "lots"}, expectErr: true},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v-%v %v", i, tt.minRank, tt.maxRank, tt.joiner.ID), func(t *testing.T) {
+			l, store, _ := testLobby(t)
+			host := Player{ID: "host", Rank: "2k"}
+			listing, err := l.List(host, nineByNine, tt.minRank, tt.maxRank)
+			require.NoError(t, err)
+			assert.Equal(t, []Listing{listing}, l.Listings())
+
+			b, err := l.JoinListing(listing.ID, tt.joiner)
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Len(t, l.Listings(), 1)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 9, b.width)
+			checkGame(t, store, b, host, tt.joiner)
+			assert.Empty(t, l.Listings())
+
+			_, err = l.JoinListing(listing.ID, carol)
+			assert.ErrorIs(t, err, ErrOfferNotFound)
+		})
+	}
+}
+
+func TestLobbyListingErrors(t *testing.T) {
+	l, _, _ := testLobby(t)
+	_, err := l.List(alice, GameSettings{Size: 2}, 0, 0)
+	assert.ErrorIs(t, err, ErrBoardSize)
+	_, err = l.List(Player{Name: "no id"}, GameSettings{Size: 9}, 0, 0)
+	assert.Error(t, err)
+	_, err = l.List(alice, GameSettings{Size: 9}, Dan(1), Kyu(1))
+	assert.Error(t, err)
+
+	listing, err := l.List(alice, GameSettings{Size: 9}, 0, 0)
+	require.NoError(t, err)
+	_, err = l.JoinListing(listing.ID, alice)
+	assert.Error(t, err)
+
+	assert.ErrorIs(t, l.Cancel(listing.ID, "bob"), ErrOfferNotFound)
+	require.NoError(t, l.Cancel(listing.ID, "alice"))
+	assert.Empty(t, l.Listings())
+}
+
+func TestLobbySeeks(t *testing.T) {
+	l, store, _ := testLobby(t)
+	settings := GameSettings{Size: 19, Rules: Japanese}
+
+	_, first, err := l.Seek(alice, settings, 1500, 100)
+	require.NoError(t, err)
+	assert.NotEmpty(t, first)
+
+	// too far away for alice's band
+	_, id, err := l.Seek(bob, settings, 1650, 300)
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	// different settings don't match
+	_, id, err = l.Seek(carol, GameSettings{Size: 9}, 1500, 100)
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	// within alice's band, but alice is outside dave's
+	dave := Player{ID: "dave"}
+	_, id, err = l.Seek(dave, settings, 1560, 50)
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	// within both alice's and bob's bands, so pairs with alice, who has
+	// been waiting longest
+	erin := Player{ID: "erin"}
+	b, id, err := l.Seek(erin, settings, 1590, 200)
+	require.NoError(t, err)
+	assert.Empty(t, id)
+	checkGame(t, store, b, alice, erin)
+	assert.Equal(t, Japanese, b.Rules())
+
+	assert.ErrorIs(t, l.Cancel(first, "alice"), ErrOfferNotFound)
+
+	// the same player doesn't pair with themselves
+	_, id, err = l.Seek(bob, settings, 1650, 300)
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	_, _, err = l.Seek(alice, settings, 1500, -1)
+	assert.Error(t, err)
+}
+
+func TestLobbySeekSettings(t *testing.T) {
+	tests := []struct {
+		a, b        GameSettings
+		expectMatch bool
+		// the game the seeks match for can't be saved
+		expectErr bool
+	}{
+		{a: GameSettings{Size: 19}, b: GameSettings{Size: 19, Rules: DefaultRules}, expectMatch: true},
+		{a: GameSettings{Size: 19, Rules: Japanese}, b: GameSettings{Size: 19, Rules: Chinese}},
+		{a: GameSettings{Size: 19, Rules: Ruleset{Komi: 5}}, b: GameSettings{Size: 19, Rules: Ruleset{Komi: 6}}},
+		{a: GameSettings{Size: 19, Rules: AtariGo}, b: GameSettings{Size: 19, Rules: DefaultRules}},
+		{a: GameSettings{Size: 19}, b: GameSettings{Size: 19, TimeControl: TimeControl{Main: time.Hour}}},
+		// rules that can't be compared with == don't panic
+		{
+			a:         GameSettings{Size: 9, Rules: bannedPoints{Ruleset: DefaultRuleset, points: []Point{{X: 4, Y: 4}}}},
+			b:         GameSettings{Size: 9, Rules: bannedPoints{Ruleset: DefaultRuleset, points: []Point{{X: 4, Y: 4}}}},
+			expectErr: true,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			l, _, _ := testLobby(t)
+			_, _, err := l.Seek(alice, tt.a, 1500, 100)
+			require.NoError(t, err)
+
+			_, id, err := l.Seek(bob, tt.b, 1500, 100)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectMatch, id == "")
+		})
+	}
+}
+
+func TestLobbyChallenges(t *testing.T) {
+	l, store, _ := testLobby(t)
+	settings := GameSettings{Size: 13}
+
+	_, err := l.Challenge(alice, alice, settings)
+	assert.Error(t, err)
+
+	c, err := l.Challenge(alice, bob, settings)
+	require.NoError(t, err)
+	assert.Equal(t, []Challenge{c}, l.Challenges("alice"))
+	assert.Equal(t, []Challenge{c}, l.Challenges("bob"))
+	assert.Empty(t, l.Challenges("carol"))
+
+	// only bob can accept
+	_, err = l.Accept(c.ID, "alice")
+	assert.ErrorIs(t, err, ErrOfferNotFound)
+
+	b, err := l.Accept(c.ID, "bob")
+	require.NoError(t, err)
+	checkGame(t, store, b, alice, bob)
+	assert.Empty(t, l.Challenges("bob"))
+
+	declined, err := l.Challenge(alice, carol, settings)
+	require.NoError(t, err)
+	assert.ErrorIs(t, l.Decline(declined.ID, "bob"), ErrOfferNotFound)
+	require.NoError(t, l.Decline(declined.ID, "carol"))
+	_, err = l.Accept(declined.ID, "carol")
+	assert.ErrorIs(t, err, ErrOfferNotFound)
+
+	cancelled, err := l.Challenge(alice, carol, settings)
+	require.NoError(t, err)
+	require.NoError(t, l.Cancel(cancelled.ID, "alice"))
+	assert.Empty(t, l.Challenges("carol"))
+}
+
+func TestLobbyExpiry(t *testing.T) {
+	_, err := NewLobby(NewMemoryStore(), WithOfferTTL(0))
+	assert.Error(t, err)
+	_, err = NewLobby(nil)
+	assert.Error(t, err)
+
+	l, _, wait := testLobby(t, WithOfferTTL(time.Minute))
+	settings := GameSettings{Size: 9}
+
+	listing, err := l.List(alice, settings, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC), listing.Expires)
+	c, err := l.Challenge(alice, bob, settings)
+	require.NoError(t, err)
+	_, seek, err := l.Seek(alice, settings, 1500, 100)
+	require.NoError(t, err)
+
+	wait(30 * time.Second)
+	later, err := l.List(carol, settings, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, l.Listings(), 2)
+
+	wait(30 * time.Second)
+	l.Expire()
+	assert.Equal(t, []Listing{later}, l.Listings())
+	_, err = l.JoinListing(listing.ID, bob)
+	assert.ErrorIs(t, err, ErrOfferNotFound)
+	_, err = l.Accept(c.ID, "bob")
+	assert.ErrorIs(t, err, ErrOfferNotFound)
+	assert.ErrorIs(t, l.Cancel(seek, "alice"), ErrOfferNotFound)
+
+	// a stale seek doesn't get paired
+	_, id, err := l.Seek(bob, settings, 1500, 100)
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+}
+
+func TestTimeControlString(t *testing.T) {
+	assert.Equal(t, "none", TimeControl{}.String())
+	assert.Equal(t, "10m0s", TimeControl{Main: 10 * time.Minute}.String())
+	assert.Equal(t, "1h0m0s+5x30s", TimeControl{Main: time.Hour, Periods: 5, Period: 30 * time.Second}.String())
+}
@@ -0,0 +1,96 @@
+package gogo
+
+// LogLevel is how important a log record is. The levels have the same
+// values as log/slog's, so they can be converted with slog.Level(level).
+type LogLevel int
+
+const (
+	// LevelDebug is for tracing how the board works out strings and
+	// neighbours.
+	LevelDebug LogLevel = -4
+	// LevelInfo is for moves: placements, captures, passes and ko.
+	LevelInfo LogLevel = 0
+	// LevelWarn is for moves that were rejected.
+	LevelWarn LogLevel = 4
+)
+
+// String ...
+func (l LogLevel) String() string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	default:
+		return "WARN"
+	}
+}
+
+// Logger receives structured records of what happens on a board. Like
+// log/slog, args are alternating keys and values, e.g.
+//
+//	logger.Log(LevelInfo, "place", "player", "black", "point", "C4", "move", 1)
+//
+// A *slog.Logger can be used by wrapping it in a LoggerFunc:
+//
+//	gogo.LoggerFunc(func(level gogo.LogLevel, msg string, args ...interface{}) {
+//		logger.Log(ctx, slog.Level(level), msg, args...)
+//	})
+type Logger interface {
+	Log(level LogLevel, msg string, args ...interface{})
+}
+
+// LoggerFunc adapts a function to a Logger.
+type LoggerFunc func(level LogLevel, msg string, args ...interface{})
+
+// Log ...
+func (f LoggerFunc) Log(level LogLevel, msg string, args ...interface{}) {
+	f(level, msg, args...)
+}
+
+// The messages a board logs, and the keys that go with them:
+//
+//	place       player, point, move
+//	capture     player, points, count
+//	ko          point
+//	pass        player, move
+//	reject      player, point, error
+//	game over   reason, result
+//	conditional player, point, and error if the queued answer couldn't
+//	            be played; or player, cancelled when the opponent didn't
+//	            play a move that was answered
+//
+// At LevelDebug, the board also traces the points and strings it
+// looks at.
+const (
+	logPlace       = "place"
+	logCapture     = "capture"
+	logKo          = "ko"
+	logPass        = "pass"
+	logReject      = "reject"
+	logGameOver    = "game over"
+	logConditional = "conditional"
+)
+
+// WithLogger sends structured records of every move to l. Boards don't
+// log anything without one.
+func WithLogger(l Logger) Option {
+	return func(b *Board) error {
+		b.logger = l
+		return nil
+	}
+}
+
+// SetLogger sends structured records of every move from now on to l, or
+// stops logging if l is nil. It's for boards that weren't created with
+// WithLogger, like ones loaded from a Store.
+func (b *Board) SetLogger(l Logger) {
+	b.logger = l
+}
+
+// log sends a record to the board's logger, if it has one.
+func (b Board) log(level LogLevel, msg string, args ...interface{}) {
+	if b.logger != nil {
+		b.logger.Log(level, msg, args...)
+	}
+}
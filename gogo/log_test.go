@@ -0,0 +1,125 @@
+package gogo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logRecord ...
+type logRecord struct {
+	level LogLevel
+	msg   string
+	attrs map[string]interface{}
+}
+
+// recordLogger keeps every record at or above level.
+type recordLogger struct {
+	level   LogLevel
+	records []logRecord
+}
+
+func (l *recordLogger) Log(level LogLevel, msg string, args ...interface{}) {
+	if level < l.level {
+		return
+	}
+	attrs := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[fmt.Sprint(args[i])] = args[i+1]
+	}
+	l.records = append(l.records, logRecord{level: level, msg: msg, attrs: attrs})
+}
+
+func (l *recordLogger) messages() []string {
+	out := []string{}
+	for _, r := range l.records {
+		out = append(out, r.msg)
+	}
+	return out
+}
+
+func TestLogging(t *testing.T) {
+	tests := []struct {
+		actions []string
+		expect  []string
+	}{
+		{actions: []string{"A1"}, expect: []string{logPlace}},
+		{actions: []string{"B1", "A1", "A2"}, expect: []string{logPlace, logPlace, logPlace, logCapture}},
+		{
+			actions: []string{"A2", "C1", "B3", "C3", "B1", "D2", "pass", "B2", "C2"},
+			expect: []string{
+				logPlace, logPlace, logPlace, logPlace, logPlace, logPlace, logPass, logPlace,
+				logPlace, logCapture, logKo,
+			},
+		},
+		{actions: []string{"A1", "A1"}, expect: []string{logPlace, logReject}},
+		{actions: []string{"Z9"}, expect: []string{logReject}},
+		{actions: []string{"A1", "pass", "pass"}, expect: []string{logPlace, logPass, logPass, logGameOver}},
+		{actions: []string{"resign:black", "A1"}, expect: []string{logGameOver, logReject}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			logger := &recordLogger{level: LevelInfo}
+			board, err := NewBoard(4, WithLogger(logger))
+			require.NoError(t, err)
+			for _, a := range tt.actions {
+				_ = play(&board, []string{a})
+			}
+			assert.Equal(t, tt.expect, logger.messages())
+		})
+	}
+}
+
+func TestLogRecords(t *testing.T) {
+	logger := &recordLogger{level: LevelInfo}
+	board, err := NewBoard(4, WithLogger(logger))
+	require.NoError(t, err)
+	require.NoError(t, play(&board, []string{"B1", "A1", "A2"}))
+	_, err = board.Play("A2")
+	require.Error(t, err)
+
+	require.Len(t, logger.records, 5)
+	assert.Equal(t, map[string]interface{}{"player": blackPlayer, "point": "A2", "move": 3}, logger.records[2].attrs)
+	assert.Equal(t, map[string]interface{}{"player": blackPlayer, "points": []string{"A1"}, "count": 1}, logger.records[3].attrs)
+
+	reject := logger.records[4]
+	assert.Equal(t, LevelWarn, reject.level)
+	assert.Equal(t, whitePlayer, reject.attrs["player"])
+	assert.ErrorIs(t, reject.attrs["error"].(error), ErrOccupied)
+}
+
+func TestLoggingIsOptional(t *testing.T) {
+	// replaying, undoing and rebuilding don't log the moves again
+	logger := &recordLogger{level: LevelDebug}
+	board, err := NewBoard(4, WithLogger(logger))
+	require.NoError(t, err)
+	require.NoError(t, play(&board, []string{"A1", "B1", "undo"}))
+	assert.Equal(t, []string{logPlace, logPlace}, logger.messages())
+
+	_, err = board.At(1)
+	require.NoError(t, err)
+	assert.Len(t, logger.records, 2)
+
+	// the logger can be turned off, or set on boards that were loaded
+	board.SetLogger(nil)
+	require.NoError(t, play(&board, []string{"B1"}))
+	assert.Len(t, logger.records, 2)
+
+	store := NewMemoryStore()
+	require.NoError(t, store.Save(&board))
+	loaded, err := store.Load(board.Code())
+	require.NoError(t, err)
+	loaded.SetLogger(logger)
+	require.NoError(t, play(&loaded, []string{"C1"}))
+	assert.Len(t, logger.records, 3)
+
+	// nothing is written to stdout; the debug traces go to the logger
+	assert.Nil(t, board.getString(2, 2, blackPiece))
+	loaded.getString(1, 1, blackPiece)
+	assert.Equal(t, LevelDebug, logger.records[len(logger.records)-1].level)
+}
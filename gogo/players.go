@@ -0,0 +1,74 @@
+package gogo
+
+import "fmt"
+
+// MaxPlayers is the most players a board can be created for.
+const MaxPlayers int = 4
+
+// colours are the stones players use, in turn order; a game with n
+// players uses the first n.
+var colours = []rune{blackPiece, whitePiece, redPiece, greenPiece}
+
+// colourNames maps each colour to the player who plays it.
+var colourNames = map[rune]string{
+	blackPiece: blackPlayer,
+	whitePiece: whitePlayer,
+	redPiece:   redPlayer,
+	greenPiece: greenPlayer,
+}
+
+// WithPlayers sets how many players share the board, from 2 up to
+// MaxPlayers. Players take turns in the order black, white, red, green.
+// Every player's stones capture any other player's, and the game ends
+// once every player passes in a row.
+func WithPlayers(n int) Option {
+	return func(b *Board) error {
+		if n < 2 || n > MaxPlayers {
+			return fmt.Errorf("%v players isn't between 2 and %v", n, MaxPlayers)
+		}
+		b.colours = colours[:n]
+		return nil
+	}
+}
+
+// Players returns the players in turn order.
+func (b Board) Players() []string {
+	out := []string{}
+	for _, p := range b.players() {
+		out = append(out, colourToPlayer(p))
+	}
+	return out
+}
+
+// PrisonersOf returns how many stones player has captured. A stone that
+// captures its own string gives the stones to the player whose turn is
+// next.
+func (b Board) PrisonersOf(player string) int {
+	return b.prisoners[playerToColour(player)]
+}
+
+// isPlaying reports whether player is one of the board's players.
+func (b Board) isPlaying(player string) bool {
+	for _, p := range b.players() {
+		if colourToPlayer(p) == player {
+			return true
+		}
+	}
+	return false
+}
+
+// colourToPlayer ...
+func colourToPlayer(piece rune) string {
+	return colourNames[piece]
+}
+
+// playerToColour is the inverse of colourToPlayer, returning emptySpace
+// for unknown players.
+func playerToColour(player string) rune {
+	for c, name := range colourNames {
+		if name == player {
+			return c
+		}
+	}
+	return emptySpace
+}
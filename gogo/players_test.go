@@ -0,0 +1,163 @@
+package gogo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateMultiPlayerBoard(t *testing.T) {
+	tests := []struct {
+		players int
+		expect  []string
+	}{
+		{players: 0},
+		{players: 1},
+		{players: 5},
+		{players: 2, expect: []string{blackPlayer, whitePlayer}},
+		{players: 3, expect: []string{blackPlayer, whitePlayer, redPlayer}},
+		{players: 4, expect: []string{blackPlayer, whitePlayer, redPlayer, greenPlayer}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v players %v", i, tt.players), func(t *testing.T) {
+			board, err := NewBoard(9, WithPlayers(tt.players))
+			if tt.expect == nil {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, board.Players())
+		})
+	}
+}
+
+func TestMultiPlayerGame(t *testing.T) {
+	tests := []struct {
+		players         int
+		width           int
+		actions         []string
+		expect          string
+		expectToMove    string
+		expectOver      bool
+		expectPrisoners map[string]int
+		expectScore     map[string]int
+	}{
+		{
+			players:      3,
+			width:        4,
+			actions:      []string{"A1", "B1", "C1"},
+			expect:       "4 X X X X\n3 X X X X\n2 X X X X\n1 B W R X\n  A B C D",
+			expectToMove: blackPlayer,
+			expectScore:  map[string]int{blackPlayer: 1, whitePlayer: 1, redPlayer: 1},
+		},
+		// any other colour's stones count against a string's liberties
+		{
+			players:         3,
+			width:           4,
+			actions:         []string{"B1", "A1", "A2"},
+			expect:          "4 X X X X\n3 X X X X\n2 R X X X\n1 X B X X\n  A B C D",
+			expectToMove:    blackPlayer,
+			expectPrisoners: map[string]int{redPlayer: 1},
+			expectScore:     map[string]int{blackPlayer: 1, redPlayer: 1},
+		},
+		{
+			players:         4,
+			width:           4,
+			actions:         []string{"B1", "A1", "pass", "pass", "A2"},
+			expect:          "4 X X X X\n3 X X X X\n2 B X X X\n1 X B X X\n  A B C D",
+			expectToMove:    whitePlayer,
+			expectPrisoners: map[string]int{blackPlayer: 1},
+			expectScore:     map[string]int{blackPlayer: 16},
+		},
+		// each colour scores the area only it surrounds
+		{
+			players: 3,
+			width:   6,
+			actions: []string{"B1", "D1", "F1", "B2", "D2", "F2", "B3", "D3", "F3", "B4", "D4", "F4"},
+			expect: `4 X B X W X R
+3 X B X W X R
+2 X B X W X R
+1 X B X W X R
+  A B C D E F`,
+			expectToMove: blackPlayer,
+			expectScore:  map[string]int{blackPlayer: 8, whitePlayer: 4, redPlayer: 4},
+		},
+		// two passes aren't enough to end a three player game
+		{
+			players:      3,
+			width:        4,
+			actions:      []string{"A1", "pass", "pass"},
+			expect:       "4 X X X X\n3 X X X X\n2 X X X X\n1 B X X X\n  A B C D",
+			expectToMove: blackPlayer,
+			expectScore:  map[string]int{blackPlayer: 16},
+		},
+		{
+			players:      3,
+			width:        4,
+			actions:      []string{"A1", "pass", "pass", "pass"},
+			expect:       "4 X X X X\n3 X X X X\n2 X X X X\n1 B X X X\n  A B C D",
+			expectToMove: whitePlayer,
+			expectOver:   true,
+			expectScore:  map[string]int{blackPlayer: 16},
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v players %v actions %v", i, tt.players, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewRectBoard(tt.width, 4, WithPlayers(tt.players))
+			require.NoError(t, err)
+			require.NoError(t, play(&board, tt.actions))
+
+			assert.Equal(t, tt.expect, board.String())
+			assert.Equal(t, tt.expectToMove, board.CurrentPlayer())
+			assert.Equal(t, tt.expectOver, board.GameOver())
+
+			for _, p := range board.Players() {
+				assert.Equal(t, tt.expectPrisoners[p], board.PrisonersOf(p), "%v prisoners", p)
+				assert.Equal(t, tt.expectScore[p], board.ScoreOf(p), "%v score", p)
+			}
+
+			// the variant survives JSON, the store and replays
+			data, err := json.Marshal(&board)
+			require.NoError(t, err)
+			var fromJSON Board
+			require.NoError(t, json.Unmarshal(data, &fromJSON))
+			assert.Equal(t, board.Players(), fromJSON.Players())
+			assert.Equal(t, tt.expect, fromJSON.String())
+			assert.Equal(t, tt.expectToMove, fromJSON.CurrentPlayer())
+
+			store := NewMemoryStore()
+			require.NoError(t, store.Save(&board))
+			loaded, err := store.Load(board.Code())
+			require.NoError(t, err)
+			assert.Equal(t, board.Players(), loaded.Players())
+			assert.Equal(t, tt.expect, loaded.String())
+			assert.Equal(t, tt.expectOver, loaded.GameOver())
+
+			// but SGF only has black and white
+			assert.Error(t, board.EncodeSGF(bytes.NewBuffer(nil)))
+		})
+	}
+}
+
+func TestMultiPlayerResult(t *testing.T) {
+	board, err := NewBoard(4, WithPlayers(3))
+	require.NoError(t, err)
+
+	require.NoError(t, play(&board, []string{"A1", "B1"}))
+	res, err := board.Play("C1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.PiecesOf(redPlayer))
+
+	assert.Error(t, board.Resign(greenPlayer))
+	require.NoError(t, board.Resign(redPlayer))
+	assert.True(t, board.GameOver())
+}
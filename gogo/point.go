@@ -0,0 +1,127 @@
+package gogo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Point is a point on the board. X counts columns from 0 on the left, and
+// Y counts rows from 0 at the bottom, so (0,0) is "A1".
+//
+// Points can be written in a few notations:
+//
+//   - A1: a column letter from A..Z ( including 'I' ) and a row number
+//     counted from 1 at the bottom. This is how the board is labelled by
+//     String, and how positions are written in events and JSON.
+//   - GTP: like A1, but the letters skip 'I', as on a real board.
+//   - SGF: two lowercase letters, column then row, with rows counted from
+//     'a' at the top; this needs the height of the board.
+//   - XY: the 0-based X and Y, as "x,y".
+type Point struct {
+	X int
+	Y int
+}
+
+// ParseA1 parses a point like "C4", where the letters include 'I'.
+// Letters are case insensitive.
+func ParseA1(s string) (Point, error) {
+	return parseLettered(s, legacyLetters)
+}
+
+// ParseGTP parses a point like "J4", where the letters skip 'I'. Letters
+// are case insensitive.
+func ParseGTP(s string) (Point, error) {
+	return parseLettered(s, gtpLetters)
+}
+
+// parseLettered ...
+func parseLettered(s, letters string) (Point, error) {
+	if len(s) < 2 {
+		return Point{}, fmt.Errorf("%w, invalid input %q", ErrBadCoordinate, s)
+	}
+
+	col := strings.IndexByte(letters, strings.ToUpper(s[:1])[0])
+	if col < 0 {
+		return Point{}, fmt.Errorf("%w, invalid vertical position %q", ErrBadCoordinate, s[:1])
+	}
+
+	num := s[1:]
+	if strings.Trim(num, "0123456789") != "" {
+		return Point{}, fmt.Errorf("%w, invalid horizontal position %q", ErrBadCoordinate, num)
+	}
+	row, err := strconv.Atoi(num)
+	if err != nil || row < 1 {
+		return Point{}, fmt.Errorf("%w, invalid horizontal position %q", ErrBadCoordinate, num)
+	}
+
+	return Point{X: col, Y: row - 1}, nil
+}
+
+// ParseSGF parses SGF's two letter notation, like "cd", for a board with
+// the given number of rows.
+func ParseSGF(s string, height int) (Point, error) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'z' || s[1] < 'a' || s[1] > 'z' {
+		return Point{}, fmt.Errorf("%w, invalid SGF position %q", ErrBadCoordinate, s)
+	}
+
+	p := Point{X: int(s[0] - 'a'), Y: height - 1 - int(s[1]-'a')}
+	if p.Y < 0 {
+		return Point{}, fmt.Errorf("%w, invalid SGF position %q for board height %v", ErrBadCoordinate, s, height)
+	}
+	return p, nil
+}
+
+// ParseXY parses a 0-based point like "2,3", optionally wrapped in
+// brackets.
+func ParseXY(s string) (Point, error) {
+	bits := strings.Split(strings.Trim(s, "() "), ",")
+	if len(bits) != 2 {
+		return Point{}, fmt.Errorf("%w, invalid point %q", ErrBadCoordinate, s)
+	}
+
+	x, err := strconv.Atoi(strings.TrimSpace(bits[0]))
+	if err != nil || x < 0 {
+		return Point{}, fmt.Errorf("%w, invalid x in point %q", ErrBadCoordinate, s)
+	}
+	y, err := strconv.Atoi(strings.TrimSpace(bits[1]))
+	if err != nil || y < 0 {
+		return Point{}, fmt.Errorf("%w, invalid y in point %q", ErrBadCoordinate, s)
+	}
+
+	return Point{X: x, Y: y}, nil
+}
+
+// A1 formats the point like "C4", with letters that include 'I'.
+func (p Point) A1() string {
+	return lettered(p, legacyLetters)
+}
+
+// GTP formats the point like "J4", with letters that skip 'I'.
+func (p Point) GTP() string {
+	return lettered(p, gtpLetters)
+}
+
+// lettered ...
+func lettered(p Point, letters string) string {
+	if p.X < 0 || p.X >= len(letters) || p.Y < 0 {
+		return p.XY()
+	}
+	return fmt.Sprintf("%c%d", letters[p.X], p.Y+1)
+}
+
+// SGF formats the point in SGF's two letter notation, for a board with
+// the given number of rows.
+func (p Point) SGF(height int) string {
+	return string([]byte{byte('a' + p.X), byte('a' + height - 1 - p.Y)})
+}
+
+// XY formats the point as "x,y".
+func (p Point) XY() string {
+	return fmt.Sprintf("%d,%d", p.X, p.Y)
+}
+
+// String formats the point in A1 notation.
+func (p Point) String() string {
+	return p.A1()
+}
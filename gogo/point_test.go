@@ -0,0 +1,185 @@
+package gogo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePoint(t *testing.T) {
+	tests := []struct {
+		notation string
+		input    string
+		height   int
+		valid    bool
+		expect   Point
+	}{
+		{notation: "a1", input: ""},
+		{notation: "a1", input: "A"},
+		{notation: "a1", input: "1"},
+		{notation: "a1", input: "A0"},
+		{notation: "a1", input: "A-1"},
+		{notation: "a1", input: "A+1"},
+		{notation: "a1", input: "31"},
+		{notation: "a1", input: "A1", valid: true, expect: Point{0, 0}},
+		{notation: "a1", input: "a1", valid: true, expect: Point{0, 0}},
+		{notation: "a1", input: "I9", valid: true, expect: Point{8, 8}},
+		{notation: "a1", input: "J10", valid: true, expect: Point{9, 9}},
+		{notation: "a1", input: "z26", valid: true, expect: Point{25, 25}},
+
+		{notation: "gtp", input: "I9"},
+		{notation: "gtp", input: "A0"},
+		{notation: "gtp", input: "A1", valid: true, expect: Point{0, 0}},
+		{notation: "gtp", input: "H8", valid: true, expect: Point{7, 7}},
+		{notation: "gtp", input: "J10", valid: true, expect: Point{8, 9}},
+		{notation: "gtp", input: "t19", valid: true, expect: Point{18, 18}},
+
+		{notation: "sgf", input: "a", height: 19},
+		{notation: "sgf", input: "aA", height: 19},
+		{notation: "sgf", input: "ae", height: 4},
+		{notation: "sgf", input: "aa", height: 19, valid: true, expect: Point{0, 18}},
+		{notation: "sgf", input: "as", height: 19, valid: true, expect: Point{0, 0}},
+		{notation: "sgf", input: "dp", height: 19, valid: true, expect: Point{3, 3}},
+
+		{notation: "xy", input: ""},
+		{notation: "xy", input: "1"},
+		{notation: "xy", input: "1,2,3"},
+		{notation: "xy", input: "-1,2"},
+		{notation: "xy", input: "a,b"},
+		{notation: "xy", input: "0,0", valid: true, expect: Point{0, 0}},
+		{notation: "xy", input: "(3, 15)", valid: true, expect: Point{3, 15}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v %q", i, tt.notation, tt.input), func(t *testing.T) {
+			var got Point
+			var err error
+
+			switch tt.notation {
+			case "a1":
+				got, err = ParseA1(tt.input)
+			case "gtp":
+				got, err = ParseGTP(tt.input)
+			case "sgf":
+				got, err = ParseSGF(tt.input, tt.height)
+			case "xy":
+				got, err = ParseXY(tt.input)
+			}
+
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestFormatPoint(t *testing.T) {
+	tests := []struct {
+		point  Point
+		height int
+		a1     string
+		gtp    string
+		sgf    string
+		xy     string
+	}{
+		{point: Point{0, 0}, height: 19, a1: "A1", gtp: "A1", sgf: "as", xy: "0,0"},
+		{point: Point{8, 8}, height: 9, a1: "I9", gtp: "J9", sgf: "ia", xy: "8,8"},
+		{point: Point{3, 3}, height: 19, a1: "D4", gtp: "D4", sgf: "dp", xy: "3,3"},
+		{point: Point{25, 0}, height: 26, a1: "Z1", gtp: "25,0", sgf: "zz", xy: "25,0"},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v point %v", i, tt.xy), func(t *testing.T) {
+			assert.Equal(t, tt.a1, tt.point.A1())
+			assert.Equal(t, tt.a1, tt.point.String())
+			assert.Equal(t, tt.gtp, tt.point.GTP())
+			assert.Equal(t, tt.sgf, tt.point.SGF(tt.height))
+			assert.Equal(t, tt.xy, tt.point.XY())
+
+			// and they all parse back to the same point
+			p, err := ParseA1(tt.a1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.point, p)
+
+			p, err = ParseSGF(tt.sgf, tt.height)
+			require.NoError(t, err)
+			assert.Equal(t, tt.point, p)
+
+			p, err = ParseXY(tt.xy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.point, p)
+		})
+	}
+}
+
+func TestPlacePoint(t *testing.T) {
+	tests := []struct {
+		size   int
+		point  Point
+		valid  bool
+		expect string
+	}{
+		{size: 4, point: Point{-1, 0}},
+		{size: 4, point: Point{0, -1}},
+		{size: 4, point: Point{4, 0}},
+		{size: 4, point: Point{0, 4}},
+		{
+			size:  4,
+			point: Point{2, 1},
+			valid: true,
+			expect: `4 X X X X
+3 X X X X
+2 X X B X
+1 X X X X
+  A B C D`,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v point %v", i, tt.point.XY()), func(t *testing.T) {
+			board, err := NewBoard(tt.size)
+			require.NoError(t, err)
+
+			_, err = board.Place(tt.point)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, board.String())
+			assert.Equal(t, tt.point.A1(), board.Events()[0].Position)
+		})
+	}
+}
+
+func TestCoordConversions(t *testing.T) {
+	tests := []struct {
+		c        coord
+		size     int
+		position string
+		index    int
+	}{
+		{c: coord{1, 1, blackPiece}, size: 4, position: "A1", index: 0},
+		{c: coord{2, 1, blackPiece}, size: 4, position: "A2", index: 1},
+		{c: coord{1, 2, whitePiece}, size: 4, position: "B1", index: 4},
+		{c: coord{4, 4, emptySpace}, size: 4, position: "D4", index: 15},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v", i, tt.position), func(t *testing.T) {
+			assert.Equal(t, tt.position, tt.c.AsPosition())
+			assert.Equal(t, fmt.Sprintf("{%v: %v}", tt.position, string(tt.c.val)), tt.c.String())
+			assert.Equal(t, tt.index, tt.c.Index(tt.size))
+			assert.Equal(t, tt.c, coordFromIndex(tt.index, tt.size, tt.size, tt.c.val))
+		})
+	}
+}
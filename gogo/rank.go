@@ -0,0 +1,70 @@
+package gogo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rank is a player's strength, from 30 kyu up to 9 dan. Ranks compare in
+// order of strength, so 1 kyu is one less than 1 dan; the zero Rank is
+// unranked.
+type Rank int
+
+const (
+	// MinKyu is the weakest kyu rank.
+	MinKyu int = 30
+	// MaxDan is the strongest dan rank.
+	MaxDan int = 9
+)
+
+// Kyu returns the rank of n kyu, from 1 up to MinKyu.
+func Kyu(n int) Rank {
+	return Rank(MinKyu - n + 1)
+}
+
+// Dan returns the rank of n dan, from 1 up to MaxDan.
+func Dan(n int) Rank {
+	return Rank(MinKyu + n)
+}
+
+// ParseRank parses a rank like "3k", "15 kyu", "2d" or "4 dan". Case
+// and spaces don't matter; "" is unranked.
+func ParseRank(s string) (Rank, error) {
+	s = strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	if s == "" {
+		return 0, nil
+	}
+
+	num := strings.TrimRight(s, "abcdefghijklmnopqrstuvwxyz")
+	n, err := strconv.Atoi(num)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rank %q", s)
+	}
+
+	switch s[len(num):] {
+	case "k", "kyu":
+		if n < 1 || n > MinKyu {
+			return 0, fmt.Errorf("invalid rank %q, kyu ranks go from %v to 1", s, MinKyu)
+		}
+		return Kyu(n), nil
+	case "d", "dan":
+		if n < 1 || n > MaxDan {
+			return 0, fmt.Errorf("invalid rank %q, dan ranks go from 1 to %v", s, MaxDan)
+		}
+		return Dan(n), nil
+	}
+	return 0, fmt.Errorf("invalid rank %q", s)
+}
+
+// String formats the rank like "3k" or "2d", or "" if it's unranked.
+func (r Rank) String() string {
+	switch {
+	case r <= 0:
+		return ""
+	case int(r) <= MinKyu:
+		return fmt.Sprintf("%dk", MinKyu-int(r)+1)
+	default:
+		return fmt.Sprintf("%dd", int(r)-MinKyu)
+	}
+}
@@ -0,0 +1,49 @@
+package gogo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRank(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect Rank
+		output string
+		valid  bool
+	}{
+		{input: "", expect: 0, output: "", valid: true},
+		{input: "30k", expect: 1, output: "30k", valid: true},
+		{input: "1k", expect: 30, output: "1k", valid: true},
+		{input: "1d", expect: 31, output: "1d", valid: true},
+		{input: "9d", expect: 39, output: "9d", valid: true},
+		{input: "15 Kyu", expect: Kyu(15), output: "15k", valid: true},
+		{input: "4 DAN", expect: Dan(4), output: "4d", valid: true},
+		{input: "0k"},
+		{input: "31k"},
+		{input: "10d"},
+		{input: "3p"},
+		{input: "k"},
+		{input: "strong"},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %q", i, tt.input), func(t *testing.T) {
+			got, err := ParseRank(tt.input)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, got)
+			assert.Equal(t, tt.output, got.String())
+		})
+	}
+
+	assert.True(t, Kyu(1) < Dan(1))
+	assert.True(t, Kyu(10) < Kyu(9))
+}
@@ -0,0 +1,371 @@
+// Package ratings rates players from the results of their finished gogo
+// games, and maps their ratings to kyu and dan ranks.
+package ratings
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/seanhagen/gogogo/gogo"
+)
+
+// ErrAlreadyRated is returned when recording a game that's already
+// changed its players' ratings.
+var ErrAlreadyRated = errors.New("game has already been rated")
+
+const (
+	// perRank is how many rating points one rank, or one handicap stone,
+	// is worth.
+	perRank float64 = 100
+	// firstDan is the rating in the middle of 1 dan.
+	firstDan float64 = 2100
+	// glicko2Scale converts between ratings and the Glicko-2 scale.
+	glicko2Scale float64 = 173.7178
+	// glicko2Epsilon is how close the new volatility has to be found.
+	glicko2Epsilon float64 = 0.000001
+)
+
+// Rating is how strong a player is thought to be. Deviation is how
+// uncertain that is, and Volatility how erratic the player's results
+// are; both are only used by Glicko2.
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// Default is the rating of a new player with no rank.
+var Default = Rating{Rating: 1500, Deviation: 350, Volatility: 0.06}
+
+// Initial returns the rating of a new player who says they're the given
+// rank, or Default if they're unranked.
+func Initial(rank gogo.Rank) Rating {
+	out := Default
+	if rank > 0 {
+		out.Rating = RankRating(rank)
+	}
+	return out
+}
+
+// Rank returns the rank the rating is in.
+func (r Rating) Rank() gogo.Rank {
+	return RatingRank(r.Rating)
+}
+
+// RatingRank returns the rank a rating is in. Each rank is perRank
+// points wide, with 1 dan centred on 2100, so 1500 is 6 kyu. Ratings
+// outside the ranks are the weakest kyu or strongest dan.
+func RatingRank(rating float64) gogo.Rank {
+	rank := gogo.Dan(1) + gogo.Rank(math.Round((rating-firstDan)/perRank))
+	if rank < gogo.Kyu(gogo.MinKyu) {
+		return gogo.Kyu(gogo.MinKyu)
+	}
+	if rank > gogo.Dan(gogo.MaxDan) {
+		return gogo.Dan(gogo.MaxDan)
+	}
+	return rank
+}
+
+// RankRating returns the rating in the middle of the rank, or Default's
+// for an unranked player.
+func RankRating(r gogo.Rank) float64 {
+	if r <= 0 {
+		return Default.Rating
+	}
+	return firstDan + float64(r-gogo.Dan(1))*perRank
+}
+
+// handicapAdvantage is how many rating points black's handicap stones
+// are worth: one rank per stone.
+func handicapAdvantage(handicap int) float64 {
+	return float64(handicap) * perRank
+}
+
+// Outcome is the result of one game, from one player's point of view.
+type Outcome struct {
+	Opponent Rating
+	// Score is 1 for a win, 0.5 for a draw and 0 for a loss.
+	Score float64
+	// Advantage is how many rating points the player's handicap was
+	// worth, so it's negative for the player giving it. Expectations
+	// are worked out as if the player's rating was this much higher.
+	Advantage float64
+}
+
+// System works out a player's new rating from the games they've played
+// since it was last updated.
+type System interface {
+	Rate(r Rating, outcomes []Outcome) Rating
+}
+
+// Elo updates ratings by K times the difference between each score and
+// the score expected from the players' ratings. Deviation and Volatility
+// are left as they are.
+type Elo struct {
+	K float64
+}
+
+// Rate ...
+func (e Elo) Rate(r Rating, outcomes []Outcome) Rating {
+	change := 0.0
+	for _, o := range outcomes {
+		expect := 1 / (1 + math.Pow(10, (o.Opponent.Rating-r.Rating-o.Advantage)/400))
+		change += e.K * (o.Score - expect)
+	}
+	r.Rating += change
+	return r
+}
+
+// Glicko2 is Glickman's Glicko-2 system, which tracks how certain each
+// rating is and how consistently the player performs. Tau limits how
+// quickly volatility changes; 0.3 to 1.2 are sensible. Each call to Rate
+// is one rating period.
+type Glicko2 struct {
+	Tau float64
+}
+
+// DefaultGlicko2 is the rating system Ratings uses unless it's created
+// WithSystem.
+var DefaultGlicko2 = Glicko2{Tau: 0.5}
+
+// glickoG ...
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// Rate ...
+func (g Glicko2) Rate(r Rating, outcomes []Outcome) Rating {
+	mu := (r.Rating - 1500) / glicko2Scale
+	phi := r.Deviation / glicko2Scale
+	sigma := r.Volatility
+
+	// a player who didn't play only becomes less certain
+	if len(outcomes) == 0 {
+		r.Deviation = math.Min(math.Sqrt(phi*phi+sigma*sigma)*glicko2Scale, Default.Deviation)
+		return r
+	}
+
+	vInv, sum := 0.0, 0.0
+	for _, o := range outcomes {
+		muJ := (o.Opponent.Rating - 1500) / glicko2Scale
+		gJ := glickoG(o.Opponent.Deviation / glicko2Scale)
+		expect := 1 / (1 + math.Exp(-gJ*(mu+o.Advantage/glicko2Scale-muJ)))
+		vInv += gJ * gJ * expect * (1 - expect)
+		sum += gJ * (o.Score - expect)
+	}
+	v := 1 / vInv
+	delta := v * sum
+
+	sigma = g.volatility(phi, sigma, v, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * sum
+
+	return Rating{
+		Rating:     mu*glicko2Scale + 1500,
+		Deviation:  math.Min(phi*glicko2Scale, Default.Deviation),
+		Volatility: sigma,
+	}
+}
+
+// volatility finds the new volatility with the Illinois algorithm, as in
+// step 5 of Glickman's description of Glicko-2.
+func (g Glicko2) volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(g.Tau*g.Tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*g.Tau) < 0 {
+			k++
+		}
+		B = a - k*g.Tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > glicko2Epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
+
+// Change is how one game changed a player's rating. Started is when the
+// game's first event happened, which tells apart games that were given
+// the same code.
+type Change struct {
+	Player  string    `json:"player"`
+	Game    string    `json:"game"`
+	Started time.Time `json:"started"`
+	Time    time.Time `json:"time"`
+	Before  Rating    `json:"before"`
+	After   Rating    `json:"after"`
+}
+
+// Ratings rates players from the results of their finished games, and
+// keeps the history of their ratings in a Store. A player's first rating
+// comes from the rank they gave. It's safe for concurrent use.
+type Ratings struct {
+	mu     sync.Mutex
+	store  Store
+	system System
+	now    func() time.Time
+}
+
+// Option configures Ratings when they're created.
+type Option func(*Ratings) error
+
+// WithSystem sets how ratings are updated, instead of DefaultGlicko2.
+func WithSystem(s System) Option {
+	return func(r *Ratings) error {
+		if s == nil {
+			return fmt.Errorf("rating system can't be nil")
+		}
+		r.system = s
+		return nil
+	}
+}
+
+// New creates Ratings that keep their history in store.
+func New(store Store, opts ...Option) (*Ratings, error) {
+	if store == nil {
+		return nil, fmt.Errorf("ratings need a store for their history")
+	}
+
+	r := &Ratings{store: store, system: DefaultGlicko2, now: time.Now}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Rating returns the player's current rating.
+func (r *Ratings) Rating(p gogo.Player) (Rating, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current(p)
+}
+
+// current ...
+func (r *Ratings) current(p gogo.Player) (Rating, error) {
+	history, err := r.store.History(p.ID)
+	if err != nil {
+		return Rating{}, err
+	}
+	if len(history) > 0 {
+		return history[len(history)-1].After, nil
+	}
+	rank, err := gogo.ParseRank(p.Rank)
+	if err != nil {
+		return Rating{}, err
+	}
+	return Initial(rank), nil
+}
+
+// History returns every change to the rating of the player with the
+// given ID, oldest first.
+func (r *Ratings) History(id string) ([]Change, error) {
+	return r.store.History(id)
+}
+
+// Record updates the ratings of both players seated in a finished game
+// from its result, taking black's handicap into account, and returns
+// the changes. Each game can only be recorded once, and both changes are
+// saved or neither is.
+func (r *Ratings) Record(b *gogo.Board) ([]Change, error) {
+	code := b.Code()
+	result, ok := b.GameResult()
+	if !ok {
+		return nil, fmt.Errorf("can't rate game %q, it isn't over", code)
+	}
+	if len(b.Players()) != 2 {
+		return nil, fmt.Errorf("can't rate game %q, it doesn't have two players", code)
+	}
+	black, ok := b.Seat("black")
+	if !ok {
+		return nil, fmt.Errorf("can't rate game %q, nobody is playing black", code)
+	}
+	white, ok := b.Seat("white")
+	if !ok {
+		return nil, fmt.Errorf("can't rate game %q, nobody is playing white", code)
+	}
+	events := b.Events()
+	if len(events) == 0 {
+		return nil, fmt.Errorf("can't rate game %q, it has no events to say when it started", code)
+	}
+	started := events[0].Time.UTC().Round(0)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history, err := r.store.History(black.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range history {
+		if c.Game == code && c.Started.Equal(started) {
+			return nil, fmt.Errorf("can't rate game %q: %w", code, ErrAlreadyRated)
+		}
+	}
+
+	blackRating, err := r.current(black)
+	if err != nil {
+		return nil, err
+	}
+	whiteRating, err := r.current(white)
+	if err != nil {
+		return nil, err
+	}
+
+	blackScore := 0.5
+	switch result.Winner {
+	case "black":
+		blackScore = 1
+	case "white":
+		blackScore = 0
+	}
+	advantage := handicapAdvantage(b.Handicap())
+
+	now := r.now()
+	changes := []Change{
+		{
+			Player:  black.ID,
+			Game:    code,
+			Started: started,
+			Time:    now,
+			Before:  blackRating,
+			After:   r.system.Rate(blackRating, []Outcome{{Opponent: whiteRating, Score: blackScore, Advantage: advantage}}),
+		},
+		{
+			Player:  white.ID,
+			Game:    code,
+			Started: started,
+			Time:    now,
+			Before:  whiteRating,
+			After:   r.system.Rate(whiteRating, []Outcome{{Opponent: blackRating, Score: 1 - blackScore, Advantage: -advantage}}),
+		},
+	}
+	if err := r.store.Save(changes...); err != nil {
+		return nil, fmt.Errorf("unable to save the ratings from game %q: %w", code, err)
+	}
+	return changes, nil
+}
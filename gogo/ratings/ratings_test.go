@@ -0,0 +1,306 @@
+package ratings
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seanhagen/gogogo/gogo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	alice = gogo.Player{ID: "alice", Name: "Alice", Rank: "3k"}
+	bob   = gogo.Player{ID: "bob", Name: "Bob", Rank: "1d"}
+)
+
+func TestGlicko2(t *testing.T) {
+	// the worked example from Glickman's "Example of the Glicko-2 system"
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	outcomes := []Outcome{
+		{Opponent: Rating{Rating: 1400, Deviation: 30}, Score: 1},
+		{Opponent: Rating{Rating: 1550, Deviation: 100}, Score: 0},
+		{Opponent: Rating{Rating: 1700, Deviation: 300}, Score: 0},
+	}
+
+	got := DefaultGlicko2.Rate(player, outcomes)
+	assert.InDelta(t, 1464.06, got.Rating, 0.01)
+	assert.InDelta(t, 151.52, got.Deviation, 0.01)
+	assert.InDelta(t, 0.05999, got.Volatility, 0.00001)
+
+	// not playing only makes the rating less certain
+	idle := DefaultGlicko2.Rate(player, nil)
+	assert.Equal(t, player.Rating, idle.Rating)
+	assert.InDelta(t, 200.27, idle.Deviation, 0.01)
+	assert.Equal(t, Default.Deviation, DefaultGlicko2.Rate(Default, nil).Deviation)
+}
+
+func TestRatingSystemsHandicap(t *testing.T) {
+	systems := []System{DefaultGlicko2, Elo{K: 32}}
+
+	for i, x := range systems {
+		system := x
+		t.Run(fmt.Sprintf("test %v %T", i, system), func(t *testing.T) {
+			weak := Rating{Rating: 1500, Deviation: 100, Volatility: 0.06}
+			strong := Rating{Rating: 1700, Deviation: 100, Volatility: 0.06}
+			even := Rating{Rating: 1500, Deviation: 100, Volatility: 0.06}
+
+			// winning a handicap game against someone as many ranks
+			// stronger as there were stones counts as an even game
+			got := system.Rate(weak, []Outcome{{Opponent: strong, Score: 1, Advantage: handicapAdvantage(2)}})
+			want := system.Rate(weak, []Outcome{{Opponent: even, Score: 1}})
+			assert.InDelta(t, want.Rating, got.Rating, 0.000001)
+
+			// and winning without the stones counts for more
+			noHandicap := system.Rate(weak, []Outcome{{Opponent: strong, Score: 1}})
+			assert.Greater(t, noHandicap.Rating, got.Rating)
+		})
+	}
+}
+
+func TestElo(t *testing.T) {
+	tests := []struct {
+		outcomes     []Outcome
+		expectRating float64
+	}{
+		{outcomes: []Outcome{{Opponent: Rating{Rating: 1500}, Score: 1}}, expectRating: 1516},
+		{outcomes: []Outcome{{Opponent: Rating{Rating: 1500}, Score: 0.5}}, expectRating: 1500},
+		{outcomes: []Outcome{{Opponent: Rating{Rating: 1900}, Score: 0}}, expectRating: 1500 - 32.0/11},
+		{outcomes: []Outcome{{Opponent: Rating{Rating: 1500}, Score: 0, Advantage: 400}}, expectRating: 1500 - 320.0/11},
+		{
+			outcomes: []Outcome{
+				{Opponent: Rating{Rating: 1500}, Score: 1},
+				{Opponent: Rating{Rating: 1500}, Score: 0},
+			},
+			expectRating: 1500,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			got := Elo{K: 32}.Rate(Default, tt.outcomes)
+			assert.InDelta(t, tt.expectRating, got.Rating, 0.000001)
+			assert.Equal(t, Default.Deviation, got.Deviation)
+		})
+	}
+}
+
+func TestRatingRank(t *testing.T) {
+	tests := []struct {
+		rating     float64
+		expectRank string
+	}{
+		{rating: 2100, expectRank: "1d"},
+		{rating: 2149, expectRank: "1d"},
+		{rating: 2151, expectRank: "2d"},
+		{rating: 2049, expectRank: "1k"},
+		{rating: 1500, expectRank: "6k"},
+		{rating: -900, expectRank: "30k"},
+		{rating: -5000, expectRank: "30k"},
+		{rating: 2900, expectRank: "9d"},
+		{rating: 5000, expectRank: "9d"},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v rating %v", i, tt.rating), func(t *testing.T) {
+			rank := RatingRank(tt.rating)
+			assert.Equal(t, tt.expectRank, rank.String())
+			assert.Equal(t, rank, RatingRank(RankRating(rank)))
+		})
+	}
+
+	assert.Equal(t, Default, Initial(0))
+	assert.Equal(t, 1800.0, Initial(gogo.Kyu(3)).Rating)
+}
+
+func TestRatingsRecord(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(_ *testing.T) Store { return NewMemoryStore() },
+		"file": func(t *testing.T) Store {
+			s, err := NewFileStore(t.TempDir())
+			require.NoError(t, err)
+			return s
+		},
+	}
+
+	for name, x := range stores {
+		newStore := x
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			ratings, err := New(store)
+			require.NoError(t, err)
+			now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			ratings.now = func() time.Time { return now }
+
+			// the first ratings come from the players' ranks
+			got, err := ratings.Rating(alice)
+			require.NoError(t, err)
+			assert.Equal(t, Initial(gogo.Kyu(3)), got)
+
+			board, err := gogo.NewBoard(9, gogo.WithSeat("black", alice), gogo.WithSeat("white", bob))
+			require.NoError(t, err)
+
+			_, err = ratings.Record(&board)
+			assert.Error(t, err, "the game isn't over")
+
+			require.NoError(t, board.ResignAs(bob.ID))
+			changes, err := ratings.Record(&board)
+			require.NoError(t, err)
+			require.Len(t, changes, 2)
+			assert.Equal(t, alice.ID, changes[0].Player)
+			assert.Equal(t, board.Code(), changes[0].Game)
+			assert.Equal(t, now, changes[0].Time)
+			assert.Greater(t, changes[0].After.Rating, changes[0].Before.Rating)
+			assert.Less(t, changes[1].After.Rating, changes[1].Before.Rating)
+
+			_, err = ratings.Record(&board)
+			assert.True(t, errors.Is(err, ErrAlreadyRated))
+
+			// the history is kept in the store
+			again, err := New(store)
+			require.NoError(t, err)
+			history, err := again.History(bob.ID)
+			require.NoError(t, err)
+			assert.Equal(t, []Change{changes[1]}, history)
+			got, err = again.Rating(alice)
+			require.NoError(t, err)
+			assert.Equal(t, changes[0].After, got)
+		})
+	}
+}
+
+// withCode returns a copy of b, saved and loaded again, with its code
+// swapped for code.
+func withCode(t *testing.T, b gogo.Board, code string) gogo.Board {
+	dir := t.TempDir()
+	store, err := gogo.NewFileStore(dir, gogo.FormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(&b))
+
+	data, err := os.ReadFile(filepath.Join(dir, b.Code()+".json"))
+	require.NoError(t, err)
+	data = bytes.Replace(data, []byte(`"code":"`+b.Code()+`"`), []byte(`"code":"`+code+`"`), 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, code+".json"), data, 0o644))
+
+	out, err := store.Load(code)
+	require.NoError(t, err)
+	return out
+}
+
+func TestRatingsRecordSharedCode(t *testing.T) {
+	ratings, err := New(NewMemoryStore())
+	require.NoError(t, err)
+
+	first, err := gogo.NewBoard(9, gogo.WithSeat("black", alice), gogo.WithSeat("white", bob))
+	require.NoError(t, err)
+	require.NoError(t, first.ResignAs(bob.ID))
+	_, err = ratings.Record(&first)
+	require.NoError(t, err)
+
+	// a copy of the same game is still the same game
+	again := withCode(t, first, first.Code())
+	_, err = ratings.Record(&again)
+	assert.True(t, errors.Is(err, ErrAlreadyRated))
+
+	// but a different game that was given its code isn't
+	second, err := gogo.NewBoard(9, gogo.WithSeat("black", alice), gogo.WithSeat("white", bob))
+	require.NoError(t, err)
+	_, err = second.Play("E5")
+	require.NoError(t, err)
+	require.NoError(t, second.ResignAs(alice.ID))
+	second = withCode(t, second, first.Code())
+	changes, err := ratings.Record(&second)
+	require.NoError(t, err)
+	assert.Equal(t, first.Code(), changes[0].Game)
+	assert.True(t, second.Events()[0].Time.Equal(changes[0].Started))
+}
+
+func TestRatingsRecordAfterMarkingDeadStones(t *testing.T) {
+	ratings, err := New(NewMemoryStore())
+	require.NoError(t, err)
+
+	board, err := gogo.NewBoard(9, gogo.WithSeat("black", alice), gogo.WithSeat("white", bob))
+	require.NoError(t, err)
+	for _, in := range []string{"A1", "E5"} {
+		_, err := board.Play(in)
+		require.NoError(t, err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := board.Pass()
+		require.NoError(t, err)
+	}
+	_, err = ratings.Record(&board)
+	require.NoError(t, err)
+
+	// marking dead stones changes the result, but not which game it is
+	require.NoError(t, board.ToggleDead("A1"))
+	_, err = ratings.Record(&board)
+	assert.True(t, errors.Is(err, ErrAlreadyRated))
+	history, err := ratings.History(alice.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+// failingStore is a store that can't save anything.
+type failingStore struct {
+	*MemoryStore
+}
+
+// Save always fails.
+func (failingStore) Save(...Change) error {
+	return errors.New("disk full")
+}
+
+func TestRatingsRecordSavesBothOrNeither(t *testing.T) {
+	store := failingStore{NewMemoryStore()}
+	ratings, err := New(store)
+	require.NoError(t, err)
+
+	board, err := gogo.NewBoard(9, gogo.WithSeat("black", alice), gogo.WithSeat("white", bob))
+	require.NoError(t, err)
+	require.NoError(t, board.ResignAs(bob.ID))
+	_, err = ratings.Record(&board)
+	require.Error(t, err)
+
+	for _, p := range []gogo.Player{alice, bob} {
+		history, err := ratings.History(p.ID)
+		require.NoError(t, err)
+		assert.Empty(t, history)
+	}
+}
+
+func TestRatingsRecordErrors(t *testing.T) {
+	ratings, err := New(NewMemoryStore(), WithSystem(Elo{K: 16}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		opts []gogo.Option
+	}{
+		{opts: []gogo.Option{gogo.WithSeat("black", alice)}},
+		{opts: []gogo.Option{gogo.WithSeat("white", bob)}},
+		{opts: []gogo.Option{gogo.WithSeat("black", alice), gogo.WithSeat("white", gogo.Player{ID: "x", Rank: "99k"})}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			board, err := gogo.NewBoard(9, tt.opts...)
+			require.NoError(t, err)
+			require.NoError(t, board.Resign("white"))
+			_, err = ratings.Record(&board)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err = New(nil)
+	assert.Error(t, err)
+	_, err = New(NewMemoryStore(), WithSystem(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package ratings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store keeps the history of every player's rating.
+type Store interface {
+	// Save adds changes to their players' histories, all of them or, if
+	// it fails, none.
+	Save(changes ...Change) error
+	// History returns every change to the player's rating, oldest first.
+	History(id string) ([]Change, error)
+}
+
+// MemoryStore keeps rating history in memory. It's safe for concurrent
+// use.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	history map[string][]Change
+}
+
+// NewMemoryStore ...
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{history: map[string][]Change{}}
+}
+
+// Save ...
+func (s *MemoryStore) Save(changes ...Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range changes {
+		s.history[c.Player] = append(s.history[c.Player], c)
+	}
+	return nil
+}
+
+// History ...
+func (s *MemoryStore) History(id string) ([]Change, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Change(nil), s.history[id]...), nil
+}
+
+// File is where a FileStore keeps rating history, one JSON Change per
+// line. It starts with a dot, so it can share a directory with a
+// gogo.FileStore without being listed as a game.
+const File string = ".ratings.jsonl"
+
+// FileStore appends rating history to File in a directory, usually the
+// one the games are saved in. It's safe for concurrent use within a
+// single process.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore creates dir if needed, and returns a store that keeps
+// rating history in it.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create store directory: %w", err)
+	}
+	return &FileStore{path: filepath.Join(dir, File)}, nil
+}
+
+// Save writes every change in one append, and cuts the file back to how
+// it was if that fails part way.
+func (s *FileStore) Save(changes ...Change) error {
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	for _, c := range changes {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to save ratings: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("unable to save ratings: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Truncate(info.Size())
+		f.Close()
+		return fmt.Errorf("unable to save ratings: %w", err)
+	}
+	return f.Close()
+}
+
+// History ...
+func (s *FileStore) History(id string) ([]Change, error) {
+	s.mu.Lock()
+	data, err := os.ReadFile(s.path)
+	s.mu.Unlock()
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to load ratings: %w", err)
+	}
+
+	var out []Change
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var c Change
+		if err := dec.Decode(&c); err != nil {
+			return nil, fmt.Errorf("unable to load ratings: %w", err)
+		}
+		if c.Player == id {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
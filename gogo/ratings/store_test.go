@@ -0,0 +1,35 @@
+package ratings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStoreSharesGameDirectory(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	changes := []Change{
+		{Player: "alice", Game: "ABCD", Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), After: Default},
+		{Player: "bob", Game: "ABCD", Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), After: Default},
+	}
+	require.NoError(t, store.Save(changes...))
+	_, err = os.Stat(filepath.Join(dir, File))
+	require.NoError(t, err)
+
+	// history written by another store on the same directory is read back
+	other, err := NewFileStore(dir)
+	require.NoError(t, err)
+	history, err := other.History("bob")
+	require.NoError(t, err)
+	assert.Equal(t, changes[1:], history)
+	history, err = other.History("carol")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
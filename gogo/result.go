@@ -1,11 +1,269 @@
 package gogo
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Result is the outcome of a single move: the position it left, and
+// everything that changed, so a client can show the move without working
+// it out again. Points are Points on the board that was played on.
 type Result struct {
-	blackPieces int
-	whitePieces int
+	pieces     map[string]int
+	winner     string
+	game       *GameResult
+	moveNumber int
+	player     string
+	captured   []Point
+	prisoners  map[string]int
+	ko         *Point
+	atari      []Group
+}
+
+// Group is a string of connected stones belonging to Player.
+type Group struct {
+	Player string
+	Points []Point
 }
 
 // Pieces ...
 func (r Result) Pieces() (int, int) {
-	return r.blackPieces, r.whitePieces
+	return r.pieces[blackPlayer], r.pieces[whitePlayer]
+}
+
+// PiecesOf returns how many stones player has on the board.
+func (r Result) PiecesOf(player string) int {
+	return r.pieces[player]
+}
+
+// Winner returns the player who won the game with this move under the
+// board's Rules, like five in a row in Gomoku, or "" if the game carries
+// on.
+func (r Result) Winner() string {
+	return r.winner
+}
+
+// MoveNumber returns the number of the move, counting from 1.
+func (r Result) MoveNumber() int {
+	return r.moveNumber
+}
+
+// Player returns the player who made the move.
+func (r Result) Player() string {
+	return r.player
+}
+
+// Captured returns the points of the stones the move removed, including
+// the player's own string if the move was suicide.
+func (r Result) Captured() []Point {
+	return r.captured
+}
+
+// Prisoners returns how many stones black and white have captured so
+// far, including this move.
+func (r Result) Prisoners() (int, int) {
+	return r.prisoners[blackPlayer], r.prisoners[whitePlayer]
+}
+
+// PrisonersOf returns how many stones player has captured so far,
+// including this move.
+func (r Result) PrisonersOf(player string) int {
+	return r.prisoners[player]
+}
+
+// Ko returns the point the next player can't play on because of ko, if
+// there is one.
+func (r Result) Ko() (Point, bool) {
+	if r.ko == nil {
+		return Point{}, false
+	}
+	return *r.ko, true
+}
+
+// Atari returns every group, of any player, left with a single liberty
+// after the move.
+func (r Result) Atari() []Group {
+	return r.atari
+}
+
+// GameOver returns true if the move ended the game; GameResult says how.
+func (r Result) GameOver() bool {
+	return r.game != nil
+}
+
+// GameResult returns how the game ended, if this move ended it.
+func (r Result) GameResult() (GameResult, bool) {
+	if r.game == nil {
+		return GameResult{}, false
+	}
+	return *r.game, true
+}
+
+// ResultReason is how a game was decided.
+type ResultReason string
+
+const (
+	// ReasonPoints is a win by counting the score, with komi.
+	ReasonPoints ResultReason = "points"
+	// ReasonResign is a win because the other player resigned.
+	ReasonResign ResultReason = "resign"
+	// ReasonTime is a win because the other player ran out of time.
+	ReasonTime ResultReason = "time"
+	// ReasonForfeit is a win because the other player forfeited.
+	ReasonForfeit ResultReason = "forfeit"
+	// ReasonRules is a win decided by the rules during play, like the
+	// first capture in Atari Go or five in a row in Gomoku.
+	ReasonRules ResultReason = "rules"
+	// ReasonDraw is a game with no winner, like a jigo, where the scores
+	// are equal.
+	ReasonDraw ResultReason = "draw"
+)
+
+// GameResult is the outcome of a finished game.
+type GameResult struct {
+	// Winner is the player who won, or "" for a draw. A resignation,
+	// timeout or forfeit in a game of more than two players has no
+	// winner, only a Loser.
+	Winner string `json:"winner,omitempty"`
+	// Loser is the player who resigned, ran out of time or forfeited.
+	Loser string `json:"loser,omitempty"`
+	// Reason is how the game was decided.
+	Reason ResultReason `json:"reason"`
+	// Margin is how many points the winner won by, for ReasonPoints.
+	Margin float64 `json:"margin,omitempty"`
+}
+
+// sgfReasons is how each reason is written after the winner in an SGF RE
+// property.
+var sgfReasons = map[ResultReason]string{
+	ReasonResign:  "R",
+	ReasonTime:    "T",
+	ReasonForfeit: "F",
+	ReasonRules:   "",
+}
+
+// String formats the result as an SGF RE value: "B+3.5" for black
+// winning by 3.5 points, "W+R" for white winning by resignation, "B+T"
+// on time, "W+F" by forfeit, "B+" for any other win and "0" for a draw.
+// Results without a winner, other than draws, are "?".
+func (r GameResult) String() string {
+	if r.Reason == ReasonDraw {
+		return "0"
+	}
+	if r.Winner == "" {
+		return "?"
+	}
+
+	winner := string(playerToColour(r.Winner))
+	if r.Reason == ReasonPoints {
+		return winner + "+" + strconv.FormatFloat(r.Margin, 'f', -1, 64)
+	}
+	return winner + "+" + sgfReasons[r.Reason]
+}
+
+// ParseGameResult parses an SGF RE value, like "B+3.5", "W+R", "B+Time"
+// or "Draw", for a two player game. The loser is only filled in for
+// resignations, timeouts and forfeits.
+func ParseGameResult(re string) (GameResult, error) {
+	re = strings.TrimSpace(re)
+	if re == "0" || strings.EqualFold(re, "draw") || strings.EqualFold(re, "jigo") {
+		return GameResult{Reason: ReasonDraw}, nil
+	}
+
+	bits := strings.SplitN(re, "+", 2)
+	if len(bits) != 2 || len(bits[0]) != 1 {
+		return GameResult{}, fmt.Errorf("invalid result %q", re)
+	}
+
+	out := GameResult{}
+	switch strings.ToUpper(bits[0]) {
+	case string(blackPiece):
+		out.Winner, out.Loser = blackPlayer, whitePlayer
+	case string(whitePiece):
+		out.Winner, out.Loser = whitePlayer, blackPlayer
+	default:
+		return GameResult{}, fmt.Errorf("invalid result %q, unknown winner", re)
+	}
+
+	switch reason := strings.ToUpper(bits[1]); reason {
+	case "R", "RESIGN":
+		out.Reason = ReasonResign
+	case "T", "TIME":
+		out.Reason = ReasonTime
+	case "F", "FORFEIT":
+		out.Reason = ReasonForfeit
+	case "":
+		out.Reason, out.Loser = ReasonRules, ""
+	default:
+		margin, err := strconv.ParseFloat(reason, 64)
+		if err != nil || margin <= 0 {
+			return GameResult{}, fmt.Errorf("invalid result %q", re)
+		}
+		out.Reason, out.Loser, out.Margin = ReasonPoints, "", margin
+	}
+
+	return out, nil
+}
+
+// GameResult returns how the game ended, or false if it hasn't. Games
+// that end with every player passing are decided by FinalScore, taking
+// any dead stones marked since into account; games of more than two
+// players are decided by ScoreOf, without komi. Equal scores are a
+// draw, unless the Ruleset gives black the win. Gomoku games that end
+// by passing are always a draw.
+func (b Board) GameResult() (GameResult, bool) {
+	switch b.endReason {
+	case "":
+		return GameResult{}, false
+	case endWin:
+		return GameResult{Winner: b.winner, Reason: ReasonRules}, true
+	case EventResign, EventTimeout, EventForfeit:
+		out := GameResult{Loser: b.loser, Reason: forfeitReasons[b.endReason]}
+		if players := b.players(); len(players) == 2 {
+			out.Winner = colourToPlayer(b.nextColour(playerToColour(b.loser)))
+		}
+		return out, true
+	}
+	if r, ok := b.Rules().(passResulter); ok {
+		return r.passResult(b), true
+	}
+
+	scores := map[string]float64{}
+	if len(b.players()) == 2 {
+		scores[blackPlayer], scores[whitePlayer] = b.FinalScore()
+	} else {
+		for _, p := range b.players() {
+			scores[colourToPlayer(p)] = float64(b.scores()[p])
+		}
+	}
+
+	// the winner is the highest score, by the margin over the next best
+	best, second := "", 0.0
+	for _, p := range b.players() {
+		player := colourToPlayer(p)
+		switch {
+		case best == "" || scores[player] > scores[best]:
+			if best != "" {
+				second = scores[best]
+			}
+			best = player
+		case scores[player] > second:
+			second = scores[player]
+		}
+	}
+	if scores[best] == second {
+		if len(b.players()) == 2 && b.Ruleset().BlackWinsTies {
+			return GameResult{Winner: blackPlayer, Reason: ReasonRules}, true
+		}
+		return GameResult{Reason: ReasonDraw}, true
+	}
+	return GameResult{Winner: best, Reason: ReasonPoints, Margin: scores[best] - second}, true
+}
+
+// forfeitReasons ...
+var forfeitReasons = map[EventType]ResultReason{
+	EventResign:  ReasonResign,
+	EventTimeout: ReasonTime,
+	EventForfeit: ReasonForfeit,
 }
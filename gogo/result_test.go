@@ -0,0 +1,315 @@
+package gogo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameResultSGF(t *testing.T) {
+	tests := []struct {
+		re     string
+		expect GameResult
+		// what it's written as, if that's different
+		expectRE string
+		valid    bool
+	}{
+		{re: "B+3.5", expect: GameResult{Winner: blackPlayer, Reason: ReasonPoints, Margin: 3.5}, valid: true},
+		{re: "W+12", expect: GameResult{Winner: whitePlayer, Reason: ReasonPoints, Margin: 12}, valid: true},
+		{re: "W+R", expect: GameResult{Winner: whitePlayer, Loser: blackPlayer, Reason: ReasonResign}, valid: true},
+		{
+			re:       "B+Resign",
+			expect:   GameResult{Winner: blackPlayer, Loser: whitePlayer, Reason: ReasonResign},
+			expectRE: "B+R",
+			valid:    true,
+		},
+		{re: "B+T", expect: GameResult{Winner: blackPlayer, Loser: whitePlayer, Reason: ReasonTime}, valid: true},
+		{re: "W+F", expect: GameResult{Winner: whitePlayer, Loser: blackPlayer, Reason: ReasonForfeit}, valid: true},
+		{re: "B+", expect: GameResult{Winner: blackPlayer, Reason: ReasonRules}, valid: true},
+		{re: "0", expect: GameResult{Reason: ReasonDraw}, valid: true},
+		{re: "Draw", expect: GameResult{Reason: ReasonDraw}, expectRE: "0", valid: true},
+		{re: "?"},
+		{re: "Void"},
+		{re: "R+3"},
+		{re: "B+lots"},
+		{re: "W+-1"},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v", i, tt.re), func(t *testing.T) {
+			res, err := ParseGameResult(tt.re)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, res)
+
+			expectRE := tt.expectRE
+			if expectRE == "" {
+				expectRE = tt.re
+			}
+			assert.Equal(t, expectRE, res.String())
+		})
+	}
+}
+
+func TestBoardGameResult(t *testing.T) {
+	tests := []struct {
+		opts     []Option
+		actions  []string
+		expect   GameResult
+		expectRE string
+		over     bool
+	}{
+		{actions: []string{"A1", "pass"}},
+		{
+			actions:  []string{"A1", "resign:white"},
+			expect:   GameResult{Winner: blackPlayer, Loser: whitePlayer, Reason: ReasonResign},
+			expectRE: "B+R",
+			over:     true,
+		},
+		{
+			actions:  []string{"timeout:black"},
+			expect:   GameResult{Winner: whitePlayer, Loser: blackPlayer, Reason: ReasonTime},
+			expectRE: "W+T",
+			over:     true,
+		},
+		{
+			actions:  []string{"A1", "forfeit:black"},
+			expect:   GameResult{Winner: whitePlayer, Loser: blackPlayer, Reason: ReasonForfeit},
+			expectRE: "W+F",
+			over:     true,
+		},
+		{
+			actions:  []string{"A1", "pass", "pass"},
+			expect:   GameResult{Winner: blackPlayer, Reason: ReasonPoints, Margin: 16},
+			expectRE: "B+16",
+			over:     true,
+		},
+		// komi is included
+		{
+			opts:     []Option{WithRules(Japanese)},
+			actions:  []string{"B1", "C1", "B2", "C2", "B3", "C3", "B4", "C4", "pass", "pass"},
+			expect:   GameResult{Winner: whitePlayer, Reason: ReasonPoints, Margin: 6.5},
+			expectRE: "W+6.5",
+			over:     true,
+		},
+		// and a tie is jigo
+		{
+			actions:  []string{"B1", "C1", "B2", "C2", "B3", "C3", "B4", "C4", "pass", "pass"},
+			expect:   GameResult{Reason: ReasonDraw},
+			expectRE: "0",
+			over:     true,
+		},
+		{
+			opts:     []Option{WithAtariGo()},
+			actions:  []string{"B1", "A1", "A2"},
+			expect:   GameResult{Winner: blackPlayer, Reason: ReasonRules},
+			expectRE: "B+",
+			over:     true,
+		},
+		// with more than two players, a resignation doesn't pick a winner
+		{
+			opts:     []Option{WithPlayers(3)},
+			actions:  []string{"A1", "resign:red"},
+			expect:   GameResult{Loser: redPlayer, Reason: ReasonResign},
+			expectRE: "?",
+			over:     true,
+		},
+		{
+			opts:     []Option{WithPlayers(3)},
+			actions:  []string{"A1", "B1", "pass", "pass", "pass"},
+			expect:   GameResult{Reason: ReasonDraw},
+			expectRE: "0",
+			over:     true,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewBoard(4, tt.opts...)
+			require.NoError(t, err)
+			require.NoError(t, play(&board, tt.actions))
+
+			res, ok := board.GameResult()
+			assert.Equal(t, tt.over, ok)
+			assert.Equal(t, tt.expect, res)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tt.expectRE, res.String())
+
+			store := NewMemoryStore()
+			require.NoError(t, store.Save(&board))
+			loaded, err := store.Load(board.Code())
+			require.NoError(t, err)
+			fromStore, _ := loaded.GameResult()
+			assert.Equal(t, tt.expect, fromStore)
+		})
+	}
+}
+
+func TestMoveGameResult(t *testing.T) {
+	board, err := NewBoard(4)
+	require.NoError(t, err)
+
+	res, err := board.Play("A1")
+	require.NoError(t, err)
+	_, ok := res.GameResult()
+	assert.False(t, ok)
+
+	res, err = board.Pass()
+	require.NoError(t, err)
+	_, ok = res.GameResult()
+	assert.False(t, ok)
+
+	res, err = board.Pass()
+	require.NoError(t, err)
+	game, ok := res.GameResult()
+	require.True(t, ok)
+	assert.Equal(t, "B+16", game.String())
+}
+
+func TestGameResultInSGF(t *testing.T) {
+	tests := []struct {
+		actions  []string
+		expectRE string
+	}{
+		{actions: []string{"D4", "C3", "resign:black"}, expectRE: "W+R"},
+		{actions: []string{"D4", "timeout:white"}, expectRE: "B+T"},
+		{actions: []string{"D4", "forfeit:white"}, expectRE: "B+F"},
+		{actions: []string{"D4", "pass", "pass"}, expectRE: "B+49"},
+		{actions: []string{"D4"}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewBoard(7)
+			require.NoError(t, err)
+			require.NoError(t, play(&board, tt.actions))
+
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, board.EncodeSGF(buf))
+			if tt.expectRE == "" {
+				assert.NotContains(t, buf.String(), "RE[")
+			} else {
+				assert.Contains(t, buf.String(), "RE["+tt.expectRE+"]")
+			}
+
+			loaded, err := DecodeSGF(buf)
+			require.NoError(t, err)
+			expect, expectOver := board.GameResult()
+			got, over := loaded.GameResult()
+			assert.Equal(t, expectOver, over)
+			assert.Equal(t, expect, got)
+		})
+	}
+}
+
+func TestMoveResult(t *testing.T) {
+	point := func(s string) Point {
+		p, err := ParseA1(s)
+		require.NoError(t, err)
+		return p
+	}
+
+	tests := []struct {
+		actions         []string
+		expectMove      int
+		expectPlayer    string
+		expectCaptured  []string
+		expectPrisoners [2]int
+		expectKo        string
+		expectAtari     map[string][]string
+		expectOver      bool
+	}{
+		{actions: []string{"A1"}, expectMove: 1, expectPlayer: blackPlayer},
+		{
+			actions:      []string{"A1", "B1"},
+			expectMove:   2,
+			expectPlayer: whitePlayer,
+			expectAtari:  map[string][]string{blackPlayer: {"A1"}},
+		},
+		{
+			actions:         []string{"B1", "A1", "A2"},
+			expectMove:      3,
+			expectPlayer:    blackPlayer,
+			expectCaptured:  []string{"A1"},
+			expectPrisoners: [2]int{1, 0},
+		},
+		// black takes the ko at C2, leaving its stone in atari, along
+		// with white's at C1
+		{
+			actions:         []string{"A2", "C1", "B3", "C3", "B1", "D2", "pass", "B2", "C2"},
+			expectMove:      9,
+			expectPlayer:    blackPlayer,
+			expectCaptured:  []string{"B2"},
+			expectPrisoners: [2]int{1, 0},
+			expectKo:        "B2",
+			expectAtari:     map[string][]string{blackPlayer: {"C2"}, whitePlayer: {"C1"}},
+		},
+		{
+			actions:      []string{"A1", "pass", "pass"},
+			expectMove:   3,
+			expectPlayer: blackPlayer,
+			expectOver:   true,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewBoard(4)
+			require.NoError(t, err)
+
+			last := tt.actions[len(tt.actions)-1]
+			require.NoError(t, play(&board, tt.actions[:len(tt.actions)-1]))
+			var res Result
+			if last == "pass" {
+				res, err = board.Pass()
+			} else {
+				res, err = board.Play(last)
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectMove, res.MoveNumber())
+			assert.Equal(t, tt.expectPlayer, res.Player())
+
+			captured := []Point{}
+			for _, s := range tt.expectCaptured {
+				captured = append(captured, point(s))
+			}
+			assert.ElementsMatch(t, captured, res.Captured())
+
+			black, white := res.Prisoners()
+			assert.Equal(t, tt.expectPrisoners, [2]int{black, white})
+
+			ko, ok := res.Ko()
+			assert.Equal(t, tt.expectKo != "", ok)
+			if tt.expectKo != "" {
+				assert.Equal(t, point(tt.expectKo), ko)
+			}
+
+			atari := map[string][]string{}
+			for _, g := range res.Atari() {
+				for _, p := range g.Points {
+					atari[g.Player] = append(atari[g.Player], p.A1())
+				}
+			}
+			for player, points := range tt.expectAtari {
+				assert.ElementsMatch(t, points, atari[player], player)
+			}
+			assert.Equal(t, len(tt.expectAtari), len(atari))
+
+			assert.Equal(t, tt.expectOver, res.GameOver())
+		})
+	}
+}
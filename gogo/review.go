@@ -0,0 +1,516 @@
+package gogo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// MarkupType is a kind of mark that can be drawn on a point while
+// reviewing a game. Each is named for its SGF property.
+type MarkupType string
+
+const (
+	// MarkTriangle draws a triangle.
+	MarkTriangle MarkupType = "TR"
+	// MarkSquare draws a square.
+	MarkSquare MarkupType = "SQ"
+	// MarkCircle draws a circle.
+	MarkCircle MarkupType = "CR"
+	// MarkCross draws an X.
+	MarkCross MarkupType = "MA"
+	// MarkLabel writes a short label, like "a" or "1".
+	MarkLabel MarkupType = "LB"
+)
+
+// markupTypes are the markup types in the order they're written to SGF.
+var markupTypes = []MarkupType{MarkTriangle, MarkSquare, MarkCircle, MarkCross, MarkLabel}
+
+// Markup is a mark drawn on a point. Label is only used by MarkLabel.
+type Markup struct {
+	Type  MarkupType
+	Point Point
+	Label string
+}
+
+// ReviewNode is a position in a Review: the move that led to it, and
+// what the reviewers had to say about it.
+type ReviewNode struct {
+	id       int
+	parent   *ReviewNode
+	children []*ReviewNode
+	player   string
+	point    *Point
+	comment  string
+	markup   []Markup
+}
+
+// ID identifies the node within its review, for Jump. The root is 0.
+func (n *ReviewNode) ID() int {
+	return n.id
+}
+
+// Parent returns the node before this one, or nil for the root.
+func (n *ReviewNode) Parent() *ReviewNode {
+	return n.parent
+}
+
+// Children returns the moves played from this position. The first is
+// the main line, and the rest are variations.
+func (n *ReviewNode) Children() []*ReviewNode {
+	return append([]*ReviewNode(nil), n.children...)
+}
+
+// Player returns who made the move that led here, or "" for the root.
+func (n *ReviewNode) Player() string {
+	return n.player
+}
+
+// Point returns where the move that led here was played. It's false for
+// a pass and for the root.
+func (n *ReviewNode) Point() (Point, bool) {
+	if n.point == nil {
+		return Point{}, false
+	}
+	return *n.point, true
+}
+
+// Comment ...
+func (n *ReviewNode) Comment() string {
+	return n.comment
+}
+
+// Markup ...
+func (n *ReviewNode) Markup() []Markup {
+	return append([]Markup(nil), n.markup...)
+}
+
+// is reports whether the node's move is player playing at p, or passing
+// if p is nil.
+func (n *ReviewNode) is(player string, p *Point) bool {
+	if n.player != player || (n.point == nil) != (p == nil) {
+		return false
+	}
+	return p == nil || *n.point == *p
+}
+
+// Review is a tree of positions for going over a game, starting with
+// the moves that were played as the main line. Any position can branch
+// into variations, and every position can have a comment and markup.
+// Moves are played, and navigated, from the current position.
+type Review struct {
+	game    Board
+	root    *ReviewNode
+	current *ReviewNode
+	board   Board
+	nodes   []*ReviewNode
+}
+
+// NewReview creates a review of a game, with its moves as the main line,
+// positioned at the start of the game.
+func NewReview(game Board) (*Review, error) {
+	if game.board == nil {
+		return nil, fmt.Errorf("can't review an invalid board")
+	}
+
+	r := &Review{game: game}
+	r.root = r.newNode(nil, "", nil)
+	r.current = r.root
+	r.board = r.start()
+
+	node := r.root
+	for _, m := range game.moves {
+		var p *Point
+		if m.idx != passMove {
+			pt := game.idxToPoint(m.idx)
+			p = &pt
+		}
+		node = r.newNode(node, colourToPlayer(m.piece), p)
+	}
+	return r, nil
+}
+
+// newNode adds a node for a move after parent.
+func (r *Review) newNode(parent *ReviewNode, player string, p *Point) *ReviewNode {
+	n := &ReviewNode{id: len(r.nodes), parent: parent, player: player, point: p}
+	r.nodes = append(r.nodes, n)
+	if parent != nil {
+		parent.children = append(parent.children, n)
+	}
+	return n
+}
+
+// start returns a new board at the position the game started from,
+// which doesn't log.
+func (r *Review) start() Board {
+	b := r.game.replay(0)
+	b.logger = nil
+	return b
+}
+
+// Root returns the position before the first move.
+func (r *Review) Root() *ReviewNode {
+	return r.root
+}
+
+// Current returns the position being looked at.
+func (r *Review) Current() *ReviewNode {
+	return r.current
+}
+
+// Board returns a copy of the board at the current position.
+func (r *Review) Board() Board {
+	return r.board.replay(len(r.board.moves))
+}
+
+// Forward moves to the main line's next position.
+func (r *Review) Forward() error {
+	return r.Variation(0)
+}
+
+// Variation moves to the ith move played from the current position,
+// where 0 is the main line.
+func (r *Review) Variation(i int) error {
+	if i < 0 || i >= len(r.current.children) {
+		return fmt.Errorf("node %v has no variation %v", r.current.id, i)
+	}
+	next := r.current.children[i]
+	if err := r.board.playNode(next); err != nil {
+		return err
+	}
+	r.current = next
+	return nil
+}
+
+// Back moves to the position before the current one.
+func (r *Review) Back() error {
+	if r.current.parent == nil {
+		return fmt.Errorf("already at the start")
+	}
+	return r.Jump(r.current.parent.id)
+}
+
+// Jump moves to the position with the given ID.
+func (r *Review) Jump(id int) error {
+	if id < 0 || id >= len(r.nodes) {
+		return fmt.Errorf("no node with ID %v", id)
+	}
+
+	target := r.nodes[id]
+	var path []*ReviewNode
+	for n := target; n.parent != nil; n = n.parent {
+		path = append(path, n)
+	}
+
+	b := r.start()
+	for i := len(path) - 1; i >= 0; i-- {
+		if err := b.playNode(path[i]); err != nil {
+			return err
+		}
+	}
+	r.board, r.current = b, target
+	return nil
+}
+
+// playNode plays the move that leads to n.
+func (b *Board) playNode(n *ReviewNode) error {
+	var err error
+	if n.point == nil {
+		_, err = b.Pass()
+	} else {
+		_, err = b.Place(*n.point)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to play node %v: %w", n.id, err)
+	}
+	return nil
+}
+
+// Play plays the current player's stone at a position in A1 notation, or
+// passes for "pass", from the current position, and moves to the new
+// position. A move that's already been played from here is followed
+// rather than added again; anything else starts a new variation.
+func (r *Review) Play(input string) (*ReviewNode, error) {
+	if strings.EqualFold(input, "pass") {
+		return r.move(nil)
+	}
+	p, err := r.board.parsePoint(input)
+	if err != nil {
+		return nil, &MoveError{Player: r.board.currentPlayer, Name: input, Err: err}
+	}
+	return r.move(&p)
+}
+
+// Place plays the current player's stone at p, like Play.
+func (r *Review) Place(p Point) (*ReviewNode, error) {
+	return r.move(&p)
+}
+
+// Pass passes from the current position, like Play.
+func (r *Review) Pass() (*ReviewNode, error) {
+	return r.move(nil)
+}
+
+// move plays at p, or passes if p is nil.
+func (r *Review) move(p *Point) (*ReviewNode, error) {
+	player := r.board.currentPlayer
+	for i, c := range r.current.children {
+		if c.is(player, p) {
+			return c, r.Variation(i)
+		}
+	}
+
+	var err error
+	if p == nil {
+		_, err = r.board.Pass()
+	} else {
+		_, err = r.board.Place(*p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.current = r.newNode(r.current, player, p)
+	return r.current, nil
+}
+
+// SetComment replaces the current position's comment.
+func (r *Review) SetComment(comment string) {
+	r.current.comment = comment
+}
+
+// AddMarkup draws a mark on the current position, replacing any mark
+// already on that point.
+func (r *Review) AddMarkup(m Markup) error {
+	found := false
+	for _, t := range markupTypes {
+		found = found || t == m.Type
+	}
+	if !found {
+		return fmt.Errorf("unknown markup type %q", m.Type)
+	}
+	if _, err := r.board.pointToIdx(m.Point); err != nil {
+		return err
+	}
+	if m.Type == MarkLabel && m.Label == "" {
+		return fmt.Errorf("label on %v is empty", r.board.pointName(m.Point))
+	}
+	if m.Type != MarkLabel {
+		m.Label = ""
+	}
+
+	r.RemoveMarkup(m.Point)
+	r.current.markup = append(r.current.markup, m)
+	return nil
+}
+
+// RemoveMarkup removes the mark on a point in the current position.
+func (r *Review) RemoveMarkup(p Point) {
+	out := r.current.markup[:0]
+	for _, m := range r.current.markup {
+		if m.Point != p {
+			out = append(out, m)
+		}
+	}
+	r.current.markup = out
+}
+
+// EncodeSGF writes the review as an SGF record, with the game's details
+// in the root node like Board.EncodeSGF, every variation, comments as C
+// and markup as TR, SQ, CR, MA and LB.
+func (r *Review) EncodeSGF(w io.Writer) error {
+	root, err := r.game.sgfRoot()
+	if err != nil {
+		return err
+	}
+
+	sb := bytes.NewBuffer(nil)
+	sb.WriteString("(;" + root)
+	r.encodeNotes(sb, r.root)
+	r.encodeChildren(sb, r.root)
+	sb.WriteString(")\n")
+
+	_, err = w.Write(sb.Bytes())
+	return err
+}
+
+// encodeChildren writes the moves after n, with each variation in its
+// own game tree.
+func (r *Review) encodeChildren(sb *bytes.Buffer, n *ReviewNode) {
+	for len(n.children) == 1 {
+		n = n.children[0]
+		r.encodeNode(sb, n)
+	}
+	for _, c := range n.children {
+		sb.WriteString("(")
+		r.encodeNode(sb, c)
+		r.encodeChildren(sb, c)
+		sb.WriteString(")")
+	}
+}
+
+// encodeNode writes a node with its move.
+func (r *Review) encodeNode(sb *bytes.Buffer, n *ReviewNode) {
+	pos := ""
+	if n.point != nil {
+		pos = n.point.SGF(r.game.height)
+	}
+	sb.WriteString(fmt.Sprintf(";%c[%s]", playerToColour(n.player), pos))
+	r.encodeNotes(sb, n)
+}
+
+// encodeNotes writes a node's comment and markup.
+func (r *Review) encodeNotes(sb *bytes.Buffer, n *ReviewNode) {
+	if n.comment != "" {
+		sb.WriteString(fmt.Sprintf("C[%s]", sgfEscape(n.comment)))
+	}
+
+	byType := map[MarkupType][]string{}
+	for _, m := range n.markup {
+		v := m.Point.SGF(r.game.height)
+		if m.Type == MarkLabel {
+			v += ":" + m.Label
+		}
+		byType[m.Type] = append(byType[m.Type], v)
+	}
+	for _, t := range markupTypes {
+		values := byType[t]
+		if len(values) == 0 {
+			continue
+		}
+		sort.Strings(values)
+		sb.WriteString(string(t))
+		for _, v := range values {
+			sb.WriteString(fmt.Sprintf("[%s]", sgfEscape(v)))
+		}
+	}
+}
+
+// DecodeReview reads a whole SGF game tree, with the main line read like
+// DecodeSGF and every variation, comment and supported markup kept. Nodes
+// without a move have their comments and markup added to the position
+// before them. The review is positioned at the start of the game.
+func DecodeReview(r io.Reader) (*Review, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	game, err := DecodeSGF(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	tree, err := parseSGF(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := NewReview(game)
+	if err != nil {
+		return nil, err
+	}
+	if err := review.decodeNode(review.root, tree, true); err != nil {
+		return nil, err
+	}
+	if err := review.Jump(0); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// decodeNode adds the SGF node, and everything after it, to the review
+// after the given position.
+func (r *Review) decodeNode(parent *ReviewNode, sn *sgfNode, root bool) error {
+	if !root {
+		for _, prop := range []string{"AB", "AW", "AE"} {
+			if _, ok := sn.props[prop]; ok {
+				return fmt.Errorf("setup property %v isn't supported", prop)
+			}
+		}
+	}
+
+	node := parent
+	for _, colour := range []rune{blackPiece, whitePiece} {
+		pos, ok := sn.get(string(colour))
+		if !ok {
+			continue
+		}
+
+		if r.current != parent {
+			if err := r.Jump(parent.id); err != nil {
+				return err
+			}
+		}
+		player := colourToPlayer(colour)
+		if player != r.board.currentPlayer {
+			return fmt.Errorf("move %d is %c, %w, it's %v's turn", r.board.MoveNumber()+1, colour, ErrNotYourTurn, r.board.currentPlayer)
+		}
+
+		var p *Point
+		if pos != "" && (pos != "tt" || r.game.width > 19 || r.game.height > 19) {
+			pt, err := ParseSGF(pos, r.game.height)
+			if err != nil {
+				return fmt.Errorf("move %d: %w", r.board.MoveNumber()+1, err)
+			}
+			p = &pt
+		}
+		var err error
+		if node, err = r.move(p); err != nil {
+			return fmt.Errorf("move %d: %w", r.board.MoveNumber()+1, err)
+		}
+		break
+	}
+
+	if err := r.decodeNotes(node, sn); err != nil {
+		return err
+	}
+	for _, c := range sn.children {
+		if err := r.decodeNode(node, c, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeNotes adds an SGF node's comment and markup to n.
+func (r *Review) decodeNotes(n *ReviewNode, sn *sgfNode) error {
+	if c, ok := sn.get("C"); ok {
+		if n.comment != "" {
+			n.comment += "\n"
+		}
+		n.comment += c
+	}
+
+	for _, t := range markupTypes {
+		for _, v := range sn.props[string(t)] {
+			m := Markup{Type: t}
+			pos := v
+			if t == MarkLabel {
+				bits := strings.SplitN(v, ":", 2)
+				if len(bits) != 2 || bits[1] == "" {
+					return fmt.Errorf("invalid label %q", v)
+				}
+				pos, m.Label = bits[0], bits[1]
+			}
+
+			p, err := ParseSGF(pos, r.game.height)
+			if err != nil {
+				return err
+			}
+			if _, err := r.game.pointToIdx(p); err != nil {
+				return err
+			}
+			m.Point = p
+
+			out := n.markup[:0]
+			for _, existing := range n.markup {
+				if existing.Point != p {
+					out = append(out, existing)
+				}
+			}
+			n.markup = append(out, m)
+		}
+	}
+	return nil
+}
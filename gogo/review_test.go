@@ -0,0 +1,231 @@
+package gogo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// boardAfter returns a 9x9 board with the actions played on it.
+func boardAfter(t *testing.T, actions ...string) Board {
+	b, err := NewBoard(9)
+	require.NoError(t, err)
+	require.NoError(t, play(&b, actions))
+	return b
+}
+
+func TestReviewNavigation(t *testing.T) {
+	game := boardAfter(t, "E5", "C3", "pass", "G7")
+	r, err := NewReview(game)
+	require.NoError(t, err)
+
+	assert.Equal(t, r.Root(), r.Current())
+	assert.Equal(t, boardAfter(t).String(), r.Board().String())
+	assert.Error(t, r.Back(), "already at the start")
+
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, r.Forward())
+		assert.Equal(t, i, r.Current().ID())
+	}
+	assert.Error(t, r.Forward(), "at the end of the main line")
+	assert.Equal(t, game.String(), r.Board().String())
+	end := r.Board()
+	assert.Equal(t, blackPlayer, end.CurrentPlayer())
+
+	p, ok := r.Current().Point()
+	assert.True(t, ok)
+	assert.Equal(t, "G7", p.A1())
+	assert.Equal(t, whitePlayer, r.Current().Player())
+
+	require.NoError(t, r.Back())
+	_, ok = r.Current().Point()
+	assert.False(t, ok, "black passed")
+	assert.Equal(t, blackPlayer, r.Current().Player())
+
+	require.NoError(t, r.Jump(2))
+	assert.Equal(t, boardAfter(t, "E5", "C3").String(), r.Board().String())
+	assert.Error(t, r.Jump(5))
+	assert.Error(t, r.Jump(-1))
+
+	// the board returned is a copy
+	b := r.Board()
+	_, err = b.Play("A1")
+	require.NoError(t, err)
+	assert.Equal(t, boardAfter(t, "E5", "C3").String(), r.Board().String())
+}
+
+func TestReviewVariations(t *testing.T) {
+	r, err := NewReview(boardAfter(t, "E5", "C3", "G7"))
+	require.NoError(t, err)
+	require.NoError(t, r.Jump(1))
+
+	// playing the main line's move follows it
+	node, err := r.Play("C3")
+	require.NoError(t, err)
+	assert.Equal(t, 2, node.ID())
+	require.NoError(t, r.Back())
+
+	// anything else is a new variation
+	node, err = r.Play("G3")
+	require.NoError(t, err)
+	assert.Equal(t, 4, node.ID())
+	node, err = r.Pass()
+	require.NoError(t, err)
+	assert.Equal(t, 5, node.ID())
+	assert.Equal(t, boardAfter(t, "E5", "G3", "pass").String(), r.Board().String())
+
+	_, err = r.Play("E5")
+	assert.ErrorIs(t, err, ErrOccupied)
+	_, err = r.Play("Z99")
+	assert.ErrorIs(t, err, ErrOffBoard)
+	assert.Equal(t, 5, r.Current().ID(), "failed moves don't move")
+
+	first := r.Root().Children()[0]
+	require.Len(t, first.Children(), 2)
+	assert.Equal(t, 2, first.Children()[0].ID())
+	assert.Equal(t, 4, first.Children()[1].ID())
+
+	require.NoError(t, r.Jump(1))
+	require.NoError(t, r.Variation(1))
+	assert.Equal(t, 4, r.Current().ID())
+	assert.Error(t, r.Variation(1))
+	require.NoError(t, r.Variation(0))
+	assert.Equal(t, 5, r.Current().ID())
+}
+
+func TestReviewMarkup(t *testing.T) {
+	r, err := NewReview(boardAfter(t, "E5"))
+	require.NoError(t, err)
+	require.NoError(t, r.Forward())
+
+	r.SetComment("a strong opening")
+	require.NoError(t, r.AddMarkup(Markup{Type: MarkTriangle, Point: Point{X: 4, Y: 4}}))
+	require.NoError(t, r.AddMarkup(Markup{Type: MarkLabel, Point: Point{X: 2, Y: 2}, Label: "a"}))
+	// a second mark on a point replaces the first
+	require.NoError(t, r.AddMarkup(Markup{Type: MarkCircle, Point: Point{X: 4, Y: 4}, Label: "ignored"}))
+
+	tests := []struct {
+		markup Markup
+	}{
+		{markup: Markup{Type: "XX", Point: Point{X: 1, Y: 1}}},
+		{markup: Markup{Type: MarkSquare, Point: Point{X: 9, Y: 1}}},
+		{markup: Markup{Type: MarkLabel, Point: Point{X: 1, Y: 1}}},
+	}
+	for _, tt := range tests {
+		assert.Error(t, r.AddMarkup(tt.markup))
+	}
+
+	node := r.Current()
+	assert.Equal(t, "a strong opening", node.Comment())
+	assert.Equal(t, []Markup{
+		{Type: MarkLabel, Point: Point{X: 2, Y: 2}, Label: "a"},
+		{Type: MarkCircle, Point: Point{X: 4, Y: 4}},
+	}, node.Markup())
+
+	r.RemoveMarkup(Point{X: 2, Y: 2})
+	assert.Equal(t, []Markup{{Type: MarkCircle, Point: Point{X: 4, Y: 4}}}, node.Markup())
+
+	// notes belong to the position they were made on
+	require.NoError(t, r.Back())
+	assert.Equal(t, "", r.Current().Comment())
+	assert.Empty(t, r.Current().Markup())
+}
+
+func TestReviewSGF(t *testing.T) {
+	game, err := NewBoard(9, WithRules(Japanese), WithSeat(blackPlayer, alice), WithSeat(whitePlayer, bob))
+	require.NoError(t, err)
+	require.NoError(t, play(&game, []string{"E5", "C3", "G7"}))
+	require.NoError(t, game.Resign(whitePlayer))
+
+	r, err := NewReview(game)
+	require.NoError(t, err)
+	r.SetComment("review of [alice] vs bob")
+	require.NoError(t, r.Jump(1))
+	require.NoError(t, r.AddMarkup(Markup{Type: MarkLabel, Point: Point{X: 2, Y: 2}, Label: "a:b"}))
+	require.NoError(t, r.AddMarkup(Markup{Type: MarkSquare, Point: Point{X: 6, Y: 6}}))
+	_, err = r.Play("G3")
+	require.NoError(t, err)
+	r.SetComment(`better \ here`)
+	_, err = r.Play("C7")
+	require.NoError(t, err)
+	require.NoError(t, r.Jump(1))
+	_, err = r.Play("D4")
+	require.NoError(t, err)
+	require.NoError(t, r.Jump(2))
+	_, err = r.Pass()
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, r.EncodeSGF(buf))
+	encoded := buf.String()
+	assert.Contains(t, encoded, `C[review of [alice\] vs bob]`)
+	assert.Contains(t, encoded, `;B[ee]SQ[gc]LB[cg:a:b](;W[cg](;B[gc])(;B[]))(;W[gg]C[better \\ here];B[cc])(;W[df])`)
+
+	decoded, err := DecodeReview(strings.NewReader(encoded))
+	require.NoError(t, err)
+	assert.Equal(t, decoded.Root(), decoded.Current())
+
+	again := bytes.NewBuffer(nil)
+	require.NoError(t, decoded.EncodeSGF(again))
+	assert.Equal(t, encoded, again.String())
+
+	// the main line is the game
+	for decoded.Forward() == nil {
+	}
+	assert.Equal(t, game.String(), decoded.Board().String())
+	res, ok := decoded.game.GameResult()
+	require.True(t, ok)
+	assert.Equal(t, "B+R", res.String())
+}
+
+func TestDecodeReview(t *testing.T) {
+	tests := []struct {
+		sgf            string
+		expectChildren []int
+		expectComments []string
+		expectErr      bool
+	}{
+		{
+			sgf:            "(;SZ[9]C[root];B[ee]C[main](;W[cc];B[dd])(;W[gg]C[var]))",
+			expectChildren: []int{1, 2, 1, 0, 0},
+			expectComments: []string{"root", "main", "", "", "var"},
+		},
+		{
+			// nodes without moves add to the position before them
+			sgf:            "(;SZ[9];B[ee];C[one]TR[aa](;C[two]))",
+			expectChildren: []int{1, 0},
+			expectComments: []string{"", "one\ntwo"},
+		},
+		{sgf: "(;SZ[9];B[ee](;W[cc])(;B[gg]))", expectErr: true},
+		{sgf: "(;SZ[9];B[ee](;W[cc])(;W[ee]))", expectErr: true},
+		{sgf: "(;SZ[9];B[ee](;W[cc])(;W[gg]AB[aa]))", expectErr: true},
+		{sgf: "(;SZ[9];B[ee]LB[aa])", expectErr: true},
+		{sgf: "(;SZ[9];B[ee]TR[zz])", expectErr: true},
+		{sgf: "(;SZ[9];B[ee]", expectErr: true},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			r, err := DecodeReview(strings.NewReader(tt.sgf))
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var children []int
+			var comments []string
+			for id := 0; r.Jump(id) == nil; id++ {
+				children = append(children, len(r.Current().Children()))
+				comments = append(comments, r.Current().Comment())
+			}
+			assert.Equal(t, tt.expectChildren, children)
+			assert.Equal(t, tt.expectComments, comments)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package gogo
+
+import "fmt"
+
+// Rules decide what can be played on a board and who wins. Place checks
+// that a point is on the board and empty, then asks the rules whether
+// the move is allowed; once the stone is down the rules decide whether
+// strings without liberties are captured, and whether anyone has won.
+type Rules interface {
+	// Name identifies the rules when a game is saved, so it can be
+	// loaded again.
+	Name() string
+	// Legal returns an error if the current player can't place a stone
+	// at p, which is an empty point on the board.
+	Legal(b *Board, p Point) error
+	// Captures reports whether strings left without liberties are
+	// removed after each move.
+	Captures() bool
+	// Winner is called after each stone is placed, with the point it
+	// was placed at, and returns the player who has won or "" if the
+	// game carries on.
+	Winner(b *Board, p Point) string
+}
+
+var (
+	// DefaultRules are the rules boards use unless they're created
+	// WithRules.
+	DefaultRules Rules = DefaultRuleset
+	// AtariGo is the usual way to teach beginners: the first capture
+	// ends the game, and the player who made it wins. A stone that
+	// captures its own string hands the win to the player whose turn is
+	// next.
+	AtariGo Rules = atariGo{DefaultRuleset}
+	// FreestyleGomoku is five in a row, where a line of five or more of
+	// a player's stones wins and nothing is ever captured.
+	FreestyleGomoku Rules = Gomoku{}
+	// StandardGomoku is like FreestyleGomoku, but only a line of exactly
+	// five wins; six or more don't count.
+	StandardGomoku Rules = Gomoku{ExactlyFive: true}
+)
+
+// passResulter is implemented by rules that decide a game that ends with
+// every player passing themselves, rather than by counting the score.
+type passResulter interface {
+	passResult(b Board) GameResult
+}
+
+// endWin is how a game ends when the rules pick a winner; it isn't an
+// event, as it's the place event that won that ends the game.
+const endWin EventType = "win"
+
+// WithRules sets the rules the game is played by; boards use
+// DefaultRules otherwise.
+func WithRules(r Rules) Option {
+	return func(b *Board) error {
+		if r != nil {
+			b.rules = r
+		}
+		return nil
+	}
+}
+
+// WithAtariGo plays the game by the AtariGo rules.
+func WithAtariGo() Option {
+	return WithRules(AtariGo)
+}
+
+// Rules returns the rules the game is played by.
+func (b Board) Rules() Rules {
+	if b.rules == nil {
+		return DefaultRules
+	}
+	return b.rules
+}
+
+// Winner returns the player who won the game under its rules, like the
+// first capture in Atari Go, or "" if nobody has.
+func (b Board) Winner() string {
+	return b.winner
+}
+
+// PieceAt returns the player with a stone at p, or "" if it's empty.
+func (b Board) PieceAt(p Point) (string, error) {
+	idx, err := b.pointToIdx(p)
+	if err != nil {
+		return "", err
+	}
+	if b.board[idx] == emptySpace {
+		return "", nil
+	}
+	return colourToPlayer(b.board[idx]), nil
+}
+
+// variant returns the name of the board's rules, or "" for the default
+// rules, for saving.
+func (b Board) variant() string {
+	if r := b.Rules(); r.Name() != DefaultRules.Name() {
+		return r.Name()
+	}
+	return ""
+}
+
+// variantOptions returns the options that recreate a saved variant.
+func variantOptions(variant string) ([]Option, error) {
+	if variant == "" {
+		return nil, nil
+	}
+	for _, r := range []Rules{DefaultRules, AtariGo, FreestyleGomoku, StandardGomoku, Japanese, Chinese, AGA, NewZealand, TrompTaylor, Ing} {
+		if r.Name() == variant {
+			return []Option{WithRules(r)}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown variant %q", variant)
+}
+
+type atariGo struct {
+	Ruleset
+}
+
+// Name ...
+func (atariGo) Name() string { return "atari" }
+
+// Winner ...
+func (atariGo) Winner(b *Board, p Point) string {
+	if len(b.moves) == 0 {
+		return ""
+	}
+	last := b.moves[len(b.moves)-1]
+	if len(last.captured) == 0 {
+		return ""
+	}
+	if b.board[last.idx] != last.piece {
+		return colourToPlayer(b.nextColour(last.piece))
+	}
+	return colourToPlayer(last.piece)
+}
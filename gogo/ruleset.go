@@ -0,0 +1,302 @@
+package gogo
+
+import "fmt"
+
+// KoRule decides which repeated positions are forbidden.
+type KoRule int
+
+const (
+	// SimpleKo only forbids retaking a single stone straight away.
+	SimpleKo KoRule = iota
+	// PositionalSuperko forbids any move that recreates a position from
+	// earlier in the game.
+	PositionalSuperko
+	// SituationalSuperko forbids any move that recreates a position from
+	// earlier in the game with the same player to move.
+	SituationalSuperko
+)
+
+// ScoringMethod decides what a player's score is made of.
+type ScoringMethod int
+
+const (
+	// AreaScoring counts a player's living stones plus the empty points
+	// they surround.
+	AreaScoring ScoringMethod = iota
+	// TerritoryScoring counts the empty points a player surrounds, plus
+	// the stones they've captured and the dead stones in their
+	// territory.
+	TerritoryScoring
+)
+
+// HandicapCompensation is how many points white gets back in a handicap
+// game, on top of komi.
+type HandicapCompensation int
+
+const (
+	// NoCompensation gives white nothing for the handicap stones.
+	NoCompensation HandicapCompensation = iota
+	// CompensateN gives white a point for each handicap stone.
+	CompensateN
+	// CompensateNMinusOne gives white a point for each handicap stone
+	// after the first.
+	CompensateNMinusOne
+)
+
+// MaxHandicap is the most handicap stones a game can start with.
+const MaxHandicap int = 9
+
+// handicapKomi is the komi used in handicap games, unless it's set
+// WithKomi.
+const handicapKomi float64 = 0.5
+
+// Ruleset is a set of rules of Go, as used by a tournament: strings
+// without liberties are captured, and the game is decided by scoring
+// once every player has passed. The presets are the common rulesets,
+// saved by name; any other Ruleset can be passed to WithRules too, and
+// is saved field by field.
+type Ruleset struct {
+	// Ko is which repeated positions are forbidden.
+	Ko KoRule `json:"ko"`
+	// Suicide allows a move that leaves its own string without
+	// liberties, which removes the string.
+	Suicide bool `json:"suicide,omitempty"`
+	// Scoring is how the score is counted.
+	Scoring ScoringMethod `json:"scoring"`
+	// Komi is the points white gets for playing second, in even games.
+	Komi float64 `json:"komi"`
+	// PassStones makes a player who passes hand a stone to the other
+	// player as a prisoner, and means the game can only end with white
+	// passing, as in the AGA rules.
+	PassStones bool `json:"pass_stones,omitempty"`
+	// Handicap is what white gets back in handicap games.
+	Handicap HandicapCompensation `json:"handicap"`
+	// BlackWinsTies gives black the win when the final scores of a two
+	// player game are equal, instead of it being a draw.
+	BlackWinsTies bool `json:"black_wins_ties,omitempty"`
+
+	name string
+}
+
+var (
+	// DefaultRuleset is the rules set out in doc.go, without
+	// optional rule 7A or a ko rule beyond simple ko.
+	DefaultRuleset = Ruleset{name: "go", Ko: SimpleKo, Suicide: true, Scoring: AreaScoring}
+
+	// Japanese rules count territory, and only forbid simple ko.
+	Japanese = Ruleset{name: "japanese", Ko: SimpleKo, Scoring: TerritoryScoring, Komi: 6.5}
+	// Chinese rules count area, and forbid repeating any position.
+	Chinese = Ruleset{name: "chinese", Ko: PositionalSuperko, Scoring: AreaScoring, Komi: 7.5, Handicap: CompensateN}
+	// AGA rules count area with pass stones, so that counting territory
+	// gives the same result.
+	AGA = Ruleset{name: "aga", Ko: SituationalSuperko, Scoring: AreaScoring, Komi: 7.5, PassStones: true, Handicap: CompensateNMinusOne}
+	// NewZealand rules count area and allow suicide.
+	NewZealand = Ruleset{name: "nz", Ko: SituationalSuperko, Suicide: true, Scoring: AreaScoring, Komi: 7}
+	// TrompTaylor is the logical ruleset: area scoring, positional
+	// superko and suicide allowed.
+	TrompTaylor = Ruleset{name: "tromp-taylor", Ko: PositionalSuperko, Suicide: true, Scoring: AreaScoring, Komi: 7.5}
+	// Ing rules count area with a komi of 8, where black wins ties. Ing
+	// only allows suicide of more than one stone; here it's allowed for
+	// any string.
+	Ing = Ruleset{name: "ing", Ko: SituationalSuperko, Suicide: true, Scoring: AreaScoring, Komi: 8, Handicap: CompensateN, BlackWinsTies: true}
+)
+
+// Name ...
+func (r Ruleset) Name() string {
+	if r.name == "" {
+		return "custom"
+	}
+	return r.name
+}
+
+// Legal checks ko and suicide.
+func (r Ruleset) Legal(b *Board, p Point) error {
+	idx, err := b.pointToIdx(p)
+	if err != nil {
+		return err
+	}
+	if r.Ko == SimpleKo && idx == b.ko {
+		return fmt.Errorf("%w, the point can't be retaken straight away", ErrKo)
+	}
+
+	after, suicide := b.tryPlay(idx)
+	if suicide && !r.Suicide {
+		return ErrSuicide
+	}
+
+	if r.Ko != SimpleKo {
+		next := b.nextColour(b.nextPiece)
+		for _, h := range b.positions() {
+			if h.board == after && (r.Ko == PositionalSuperko || h.toMove == next) {
+				return fmt.Errorf("%w, it would repeat an earlier position", ErrKo)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Captures ...
+func (Ruleset) Captures() bool { return true }
+
+// Winner ...
+func (Ruleset) Winner(_ *Board, _ Point) string { return "" }
+
+// ruleset ...
+func (r Ruleset) ruleset() Ruleset { return r }
+
+// rulesetter is implemented by rules that are, or are built on, a
+// Ruleset.
+type rulesetter interface {
+	ruleset() Ruleset
+}
+
+// Ruleset returns the ruleset the game is scored by. Rules that aren't
+// built on a Ruleset, like Gomoku, use DefaultRuleset.
+func (b Board) Ruleset() Ruleset {
+	if r, ok := b.Rules().(rulesetter); ok {
+		return r.ruleset()
+	}
+	return DefaultRuleset
+}
+
+// WithKomi sets the komi, instead of the ruleset's.
+func WithKomi(komi float64) Option {
+	return func(b *Board) error {
+		b.komi = &komi
+		return nil
+	}
+}
+
+// WithHandicap gives black n stones, from 2 up to MaxHandicap, on the
+// star points before the game starts, with white playing first. The
+// board must be at least 7x7, and have odd sides for more than four
+// stones. Handicap games have a komi of 0.5 unless it's set WithKomi.
+func WithHandicap(n int) Option {
+	return func(b *Board) error {
+		if n != 0 && (n < 2 || n > MaxHandicap) {
+			return fmt.Errorf("handicap of %v isn't between 2 and %v", n, MaxHandicap)
+		}
+		b.handicap = n
+		return nil
+	}
+}
+
+// Komi returns the points white gets for playing second.
+func (b Board) Komi() float64 {
+	if b.komi != nil {
+		return *b.komi
+	}
+	if b.handicap > 0 {
+		return handicapKomi
+	}
+	return b.Ruleset().Komi
+}
+
+// Handicap returns how many handicap stones black started with.
+func (b Board) Handicap() int {
+	return b.handicap
+}
+
+// FinalScore returns black and white's scores with komi and handicap
+// compensation added to white's.
+func (b Board) FinalScore() (float64, float64) {
+	black, white := b.Score()
+	total := float64(white) + b.Komi()
+
+	switch b.Ruleset().Handicap {
+	case CompensateN:
+		total += float64(b.handicap)
+	case CompensateNMinusOne:
+		if b.handicap > 0 {
+			total += float64(b.handicap - 1)
+		}
+	}
+
+	return float64(black), total
+}
+
+// placeHandicap puts the handicap stones on the board, and makes the
+// result the position the game starts from.
+func (b *Board) placeHandicap() error {
+	if b.handicap == 0 {
+		return nil
+	}
+	if len(b.players()) != 2 {
+		return fmt.Errorf("handicap games need two players")
+	}
+
+	points, err := handicapPoints(b.handicap, b.width, b.height)
+	if err != nil {
+		return err
+	}
+	for _, p := range points {
+		idx, err := b.pointToIdx(p)
+		if err != nil {
+			return err
+		}
+		b.board[idx] = blackPiece
+	}
+	b.nextPiece, b.currentPlayer = whitePiece, whitePlayer
+
+	b.start = &position{
+		board:     append([]rune(nil), b.board...),
+		nextPiece: b.nextPiece,
+		prisoners: map[rune]int{},
+		ko:        -1,
+	}
+	return nil
+}
+
+// handicapPoints returns where n handicap stones go, in the traditional
+// order: the corners, then the sides, with the centre for odd handicaps
+// above four.
+func handicapPoints(n, width, height int) ([]Point, error) {
+	cols, rows := starLines(width), starLines(height)
+	if cols == nil || rows == nil {
+		return nil, fmt.Errorf("a %vx%v board is too small for a handicap", width, height)
+	}
+	if n > 4 && (width%2 == 0 || height%2 == 0) {
+		return nil, fmt.Errorf("a handicap of %v needs a board with odd sides", n)
+	}
+
+	low, high := Point{cols[0], rows[0]}, Point{cols[1], rows[1]}
+	mid := Point{width / 2, height / 2}
+	corners := []Point{{high.X, high.Y}, {low.X, low.Y}, {high.X, low.Y}, {low.X, high.Y}}
+	sides := []Point{{low.X, mid.Y}, {high.X, mid.Y}, {mid.X, high.Y}, {mid.X, low.Y}}
+
+	switch {
+	case n <= 4:
+		return corners[:n], nil
+	case n%2 == 1:
+		return append(append(corners, sides[:n-5]...), mid), nil
+	default:
+		return append(corners, sides[:n-4]...), nil
+	}
+}
+
+// boardState is a position and the colour to move in it, for superko.
+type boardState struct {
+	board  string
+	toMove rune
+}
+
+// positions returns every position the game has been in after a stone
+// was placed, along with the position it started from.
+func (b Board) positions() []boardState {
+	if b.history != nil {
+		return b.history
+	}
+	return []boardState{{board: string(b.board), toMove: b.nextPiece}}
+}
+
+// tryPlay returns the position that placing the next piece at idx would
+// leave, and whether the placed stone's string would be captured.
+func (b Board) tryPlay(idx int) (string, bool) {
+	sim := b
+	sim.board = append([]rune(nil), b.board...)
+	sim.prisoners = map[rune]int{}
+	sim.board[idx] = b.nextPiece
+	sim.capture(idx)
+	return string(sim.board), sim.board[idx] != b.nextPiece
+}
@@ -0,0 +1,338 @@
+package gogo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesetLegality(t *testing.T) {
+	// white's A1 is suicide, and removing it recreates the position
+	// before it was played
+	suicide := []string{"B1", "pass", "A2", "A1"}
+	// white retakes the ko at B2 straight away
+	ko := []string{"A2", "C1", "B3", "C3", "B1", "D2", "pass", "B2", "C2", "B2"}
+
+	tests := []struct {
+		rules     Ruleset
+		actions   []string
+		expectErr bool
+	}{
+		{rules: DefaultRuleset, actions: suicide},
+		{rules: Japanese, actions: suicide, expectErr: true},
+		{rules: Chinese, actions: suicide, expectErr: true},
+		{rules: AGA, actions: suicide, expectErr: true},
+		// the position repeats, but with the other player to move
+		{rules: NewZealand, actions: suicide},
+		{rules: Ing, actions: suicide},
+		{rules: TrompTaylor, actions: suicide, expectErr: true},
+
+		{rules: DefaultRuleset, actions: ko, expectErr: true},
+		{rules: Japanese, actions: ko, expectErr: true},
+		{rules: Chinese, actions: ko, expectErr: true},
+		{rules: AGA, actions: ko, expectErr: true},
+		{rules: NewZealand, actions: ko, expectErr: true},
+		{rules: TrompTaylor, actions: ko, expectErr: true},
+		{rules: Ing, actions: ko, expectErr: true},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v actions %v", i, tt.rules.Name(), strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewBoard(4, WithRules(tt.rules))
+			require.NoError(t, err)
+
+			last := tt.actions[len(tt.actions)-1]
+			require.NoError(t, play(&board, tt.actions[:len(tt.actions)-1]))
+			_, err = board.Play(last)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRulesetScoring(t *testing.T) {
+	// black walls off column A and white column D, and white captures a
+	// black stone on the way
+	actions := []string{"B1", "C1", "B2", "C2", "B3", "C3", "D4", "C4", "B4", "D3", "pass", "pass"}
+
+	tests := []struct {
+		rules       Ruleset
+		opts        []Option
+		expectScore [2]int
+		expectFinal [2]float64
+	}{
+		{rules: DefaultRuleset, expectScore: [2]int{8, 8}, expectFinal: [2]float64{8, 8}},
+		{rules: Japanese, expectScore: [2]int{4, 4}, expectFinal: [2]float64{4, 10.5}},
+		{rules: Chinese, expectScore: [2]int{8, 8}, expectFinal: [2]float64{8, 15.5}},
+		{rules: NewZealand, expectScore: [2]int{8, 8}, expectFinal: [2]float64{8, 15}},
+		{rules: Ing, expectScore: [2]int{8, 8}, expectFinal: [2]float64{8, 16}},
+		{
+			rules:       Japanese,
+			opts:        []Option{WithKomi(0)},
+			expectScore: [2]int{4, 4},
+			expectFinal: [2]float64{4, 4},
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v", i, tt.rules.Name()), func(t *testing.T) {
+			board, err := NewBoard(4, append([]Option{WithRules(tt.rules)}, tt.opts...)...)
+			require.NoError(t, err)
+			require.NoError(t, play(&board, actions))
+			require.True(t, board.GameOver())
+
+			black, white := board.Score()
+			assert.Equal(t, tt.expectScore, [2]int{black, white})
+			fblack, fwhite := board.FinalScore()
+			assert.Equal(t, tt.expectFinal, [2]float64{fblack, fwhite})
+		})
+	}
+}
+
+func TestBlackWinsTies(t *testing.T) {
+	// black takes 12 points and white the 4 in the corner, which komi 8
+	// evens out
+	actions := []string{"B1", "C1", "B2", "C2", "C3", "D2", "D3", "pass", "pass"}
+
+	tests := []struct {
+		rules  Ruleset
+		opts   []Option
+		expect GameResult
+	}{
+		{rules: Ing, expect: GameResult{Winner: "black", Reason: ReasonRules}},
+		{rules: Chinese, opts: []Option{WithKomi(8)}, expect: GameResult{Reason: ReasonDraw}},
+		{
+			rules:  Ruleset{Ko: SimpleKo, Scoring: AreaScoring, Komi: 8, BlackWinsTies: true},
+			expect: GameResult{Winner: "black", Reason: ReasonRules},
+		},
+		{rules: Ing, opts: []Option{WithKomi(7.5)}, expect: GameResult{Winner: "black", Reason: ReasonPoints, Margin: 0.5}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v", i, tt.rules.Name()), func(t *testing.T) {
+			board, err := NewBoard(4, append([]Option{WithRules(tt.rules)}, tt.opts...)...)
+			require.NoError(t, err)
+			require.NoError(t, play(&board, actions))
+
+			got, ok := board.GameResult()
+			require.True(t, ok)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestPassStones(t *testing.T) {
+	board, err := NewBoard(4, WithRules(AGA))
+	require.NoError(t, err)
+
+	// black passing last doesn't end the game
+	require.NoError(t, play(&board, []string{"A1", "pass", "pass"}))
+	assert.False(t, board.GameOver())
+	assert.Equal(t, 1, board.PrisonersOf(blackPlayer))
+	assert.Equal(t, 1, board.PrisonersOf(whitePlayer))
+
+	require.NoError(t, play(&board, []string{"pass"}))
+	assert.True(t, board.GameOver())
+	assert.Equal(t, 2, board.PrisonersOf(blackPlayer))
+}
+
+func TestHandicap(t *testing.T) {
+	tests := []struct {
+		width, height int
+		opts          []Option
+		expectStones  []string
+		expectErr     bool
+	}{
+		{width: 9, height: 9, opts: []Option{WithHandicap(2)}, expectStones: []string{"G7", "C3"}},
+		{width: 9, height: 9, opts: []Option{WithHandicap(3)}, expectStones: []string{"G7", "C3", "G3"}},
+		{
+			width:        9,
+			height:       9,
+			opts:         []Option{WithHandicap(5)},
+			expectStones: []string{"G7", "C3", "G3", "C7", "E5"},
+		},
+		{
+			width:        19,
+			height:       19,
+			opts:         []Option{WithHandicap(8)},
+			expectStones: []string{"P16", "D4", "P4", "D16", "D10", "P10", "J16", "J4"},
+		},
+		{width: 9, height: 13, opts: []Option{WithHandicap(4)}, expectStones: []string{"G10", "C4", "G4", "C10"}},
+		{width: 9, height: 9, opts: []Option{WithHandicap(1)}, expectErr: true},
+		{width: 9, height: 9, opts: []Option{WithHandicap(10)}, expectErr: true},
+		{width: 5, height: 5, opts: []Option{WithHandicap(2)}, expectErr: true},
+		{width: 10, height: 10, opts: []Option{WithHandicap(5)}, expectErr: true},
+		{width: 9, height: 9, opts: []Option{WithHandicap(2), WithPlayers(3)}, expectErr: true},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %vx%v", i, tt.width, tt.height), func(t *testing.T) {
+			board, err := NewRectBoard(tt.width, tt.height, tt.opts...)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, whitePlayer, board.CurrentPlayer())
+			assert.Equal(t, len(tt.expectStones), board.Handicap())
+			assert.Equal(t, handicapKomi, board.Komi())
+
+			black, _ := board.Score()
+			for _, s := range tt.expectStones {
+				p, err := ParseA1(s)
+				require.NoError(t, err)
+				got, err := board.PieceAt(p)
+				require.NoError(t, err)
+				assert.Equal(t, blackPlayer, got, s)
+			}
+			assert.Equal(t, tt.width*tt.height, black, "black owns the whole board")
+			assert.Equal(t, 0, board.MoveNumber())
+
+			// undoing white's first move goes back to the handicap stones
+			require.NoError(t, play(&board, []string{"A1"}))
+			require.NoError(t, board.Undo())
+			assert.Equal(t, whitePlayer, board.CurrentPlayer())
+			black, _ = board.Score()
+			assert.Equal(t, tt.width*tt.height, black)
+		})
+	}
+}
+
+func TestHandicapCompensation(t *testing.T) {
+	tests := []struct {
+		rules       Ruleset
+		expectWhite float64
+	}{
+		{rules: Japanese, expectWhite: 0.5},
+		{rules: Chinese, expectWhite: 3.5},
+		{rules: AGA, expectWhite: 2.5},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v", i, tt.rules.Name()), func(t *testing.T) {
+			board, err := NewBoard(9, WithRules(tt.rules), WithHandicap(3))
+			require.NoError(t, err)
+
+			_, white := board.FinalScore()
+			assert.Equal(t, tt.expectWhite, white)
+		})
+	}
+}
+
+func TestHandicapOnGraph(t *testing.T) {
+	_, err := NewGraphBoard(gridGraph(9), WithHandicap(2))
+	assert.Error(t, err)
+}
+
+func TestRulesetSaveAndLoad(t *testing.T) {
+	tests := []struct {
+		opts []Option
+	}{
+		{opts: []Option{WithRules(Japanese)}},
+		{opts: []Option{WithRules(Chinese), WithKomi(5.5)}},
+		{opts: []Option{WithRules(AGA), WithHandicap(4)}},
+		{opts: []Option{WithRules(TrompTaylor), WithHandicap(2), WithKomi(0)}},
+		{opts: []Option{WithHandicap(9)}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			board, err := NewBoard(9, tt.opts...)
+			require.NoError(t, err)
+			require.NoError(t, play(&board, []string{"A1", "B1"}))
+
+			check := func(t *testing.T, got Board) {
+				assert.Equal(t, board.Rules(), got.Rules())
+				assert.Equal(t, board.Komi(), got.Komi())
+				assert.Equal(t, board.Handicap(), got.Handicap())
+				assert.Equal(t, board.String(), got.String())
+				assert.Equal(t, board.CurrentPlayer(), got.CurrentPlayer())
+			}
+
+			data, err := json.Marshal(&board)
+			require.NoError(t, err)
+			var fromJSON Board
+			require.NoError(t, json.Unmarshal(data, &fromJSON))
+			check(t, fromJSON)
+
+			store := NewMemoryStore()
+			require.NoError(t, store.Save(&board))
+			loaded, err := store.Load(board.Code())
+			require.NoError(t, err)
+			check(t, loaded)
+
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, board.EncodeSGF(buf))
+			fromSGF, err := DecodeSGF(buf)
+			require.NoError(t, err)
+			check(t, fromSGF)
+		})
+	}
+}
+
+func TestCustomRulesetSaveAndLoad(t *testing.T) {
+	custom := Ruleset{Ko: SituationalSuperko, Scoring: TerritoryScoring, Komi: 5.5, PassStones: true, Handicap: CompensateN}
+	board, err := NewBoard(9, WithRules(custom))
+	require.NoError(t, err)
+	require.NoError(t, play(&board, []string{"A1", "B1"}))
+
+	data, err := json.Marshal(&board)
+	require.NoError(t, err)
+	var fromJSON Board
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.Equal(t, custom, fromJSON.Rules())
+
+	store, err := NewFileStore(t.TempDir(), FormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(&board))
+	loaded, err := store.Load(board.Code())
+	require.NoError(t, err)
+	assert.Equal(t, custom, loaded.Rules())
+	assert.Equal(t, board.String(), loaded.String())
+	assert.Equal(t, 5.5, loaded.Komi())
+}
+
+func TestDecodeSGFHandicap(t *testing.T) {
+	tests := []struct {
+		sgf       string
+		expectErr bool
+	}{
+		{sgf: "(;SZ[9]HA[2]AB[gc][cg];W[ee])"},
+		{sgf: "(;SZ[9]HA[2];W[ee])"},
+		{sgf: "(;SZ[9]AB[gc][cg];W[ee])", expectErr: true},
+		{sgf: "(;SZ[9]HA[2]AB[gc][ee];W[dd])", expectErr: true},
+		{sgf: "(;SZ[9]HA[2]AB[gc];W[ee])", expectErr: true},
+		{sgf: "(;SZ[9]HA[2]AB[gc][cg];W[ee];AB[dd])", expectErr: true},
+		{sgf: "(;SZ[9]HA[two];W[ee])", expectErr: true},
+		{sgf: "(;SZ[9]KM[lots];B[ee])", expectErr: true},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			board, err := DecodeSGF(strings.NewReader(tt.sgf))
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 2, board.Handicap())
+			assert.Equal(t, blackPlayer, board.CurrentPlayer())
+		})
+	}
+}
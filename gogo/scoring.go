@@ -0,0 +1,97 @@
+package gogo
+
+// Score counts each player's points the way the board's Ruleset says;
+// the default is area scoring. Komi isn't included; see FinalScore.
+//
+// Area is the stones a player has on the board that aren't marked dead,
+// plus the empty points surrounded only by their stones. Territory is the
+// empty points surrounded only by their stones, plus the stones they've
+// captured. Dead stones are treated as empty points, so they count
+// towards the territory they sit in, and as captured by the player whose
+// territory that is.
+func (b Board) Score() (int, int) {
+	score := b.scores()
+	return score[blackPiece], score[whitePiece]
+}
+
+// ScoreOf returns player's score, counted the same way as Score.
+func (b Board) ScoreOf(player string) int {
+	return b.scores()[playerToColour(player)]
+}
+
+// scores ...
+func (b Board) scores() map[rune]int {
+	stones, territory, dead := b.count()
+
+	score := map[rune]int{}
+	for _, p := range b.players() {
+		switch b.Ruleset().Scoring {
+		case TerritoryScoring:
+			score[p] = territory[p] + dead[p] + b.prisoners[p]
+		default:
+			score[p] = stones[p] + territory[p]
+		}
+	}
+	return score
+}
+
+// count returns, for each colour, the stones it has on the board that
+// aren't dead, the points in the regions only it surrounds ( including
+// dead stones ), and how many dead stones are in those regions.
+func (b Board) count() (map[rune]int, map[rune]int, map[rune]int) {
+	stones, territory, dead := map[rune]int{}, map[rune]int{}, map[rune]int{}
+
+	// the board with dead stones removed
+	pos := make([]rune, len(b.board))
+	for i, p := range b.board {
+		if b.dead[i] {
+			p = emptySpace
+		}
+		pos[i] = p
+		if p != emptySpace {
+			stones[p]++
+		}
+	}
+
+	seen := map[int]bool{}
+	for i, p := range pos {
+		if p != emptySpace || seen[i] {
+			continue
+		}
+
+		// flood fill the empty region, noting which colours border it
+		region := []int{}
+		borders := map[rune]bool{}
+		queue := []int{i}
+		seen[i] = true
+
+		var current int
+		for len(queue) > 0 {
+			current, queue = pop(queue)
+			region = append(region, current)
+			for _, n := range b.adjacent(current) {
+				if pos[n] != emptySpace {
+					borders[pos[n]] = true
+					continue
+				}
+				if !seen[n] {
+					seen[n] = true
+					queue = append(queue, n)
+				}
+			}
+		}
+
+		if len(borders) == 1 {
+			for owner := range borders {
+				territory[owner] += len(region)
+				for _, r := range region {
+					if b.dead[r] && b.board[r] != owner {
+						dead[owner]++
+					}
+				}
+			}
+		}
+	}
+
+	return stones, territory, dead
+}
@@ -0,0 +1,65 @@
+package gogo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAreaScoring(t *testing.T) {
+	tests := []struct {
+		size        int
+		actions     []string
+		expectBlack int
+		expectWhite int
+	}{
+		// nobody owns an empty board
+		{size: 4},
+		// a lone stone owns the whole board
+		{size: 4, actions: []string{"B2"}, expectBlack: 16},
+		// a wall down the middle splits the board
+		{
+			size:        4,
+			actions:     []string{"B1", "C1", "B2", "C2", "B3", "C3", "B4", "C4"},
+			expectBlack: 8,
+			expectWhite: 8,
+		},
+		// a white stone inside black's area is neutral until it's marked
+		// dead
+		{
+			size:        4,
+			actions:     []string{"B1", "C1", "B2", "C2", "B3", "C3", "B4", "C4", "pass", "A1"},
+			expectBlack: 4,
+			expectWhite: 9,
+		},
+		{
+			size:        4,
+			actions:     []string{"B1", "C1", "B2", "C2", "B3", "C3", "B4", "C4", "pass", "A1", "pass", "pass", "dead:A1"},
+			expectBlack: 8,
+			expectWhite: 8,
+		},
+		// toggling twice brings the stone back to life
+		{
+			size:        4,
+			actions:     []string{"B1", "C1", "B2", "C2", "B3", "C3", "B4", "C4", "pass", "A1", "pass", "pass", "dead:A1", "dead:A1"},
+			expectBlack: 4,
+			expectWhite: 9,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v actions %v", i, strings.Join(tt.actions, "_")), func(t *testing.T) {
+			board, err := NewBoard(tt.size)
+			require.NoError(t, err)
+			require.NoError(t, play(&board, tt.actions))
+
+			gotBlack, gotWhite := board.Score()
+			assert.Equal(t, tt.expectBlack, gotBlack, "black score")
+			assert.Equal(t, tt.expectWhite, gotWhite, "white score")
+		})
+	}
+}
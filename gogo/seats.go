@@ -0,0 +1,175 @@
+package gogo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrGameFull is returned when joining a game whose seats are all taken.
+var ErrGameFull = errors.New("game is full")
+
+// Player is someone playing a game. ID identifies them to the server;
+// Name and Rank, like "3k" or "2d", are for showing to people.
+type Player struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Rank string `json:"rank,omitempty"`
+}
+
+// WithSeat sits p at colour ( "black", "white", ... ) when the board is
+// created. Seats can also be taken later with Join or Nigiri.
+func WithSeat(colour string, p Player) Option {
+	return func(b *Board) error {
+		if p.ID == "" {
+			return fmt.Errorf("player for %v has no ID", colour)
+		}
+		c := playerToColour(colour)
+		if c == emptySpace {
+			return fmt.Errorf("unknown player %q", colour)
+		}
+		if b.seats == nil {
+			b.seats = map[rune]Player{}
+		}
+		b.seats[c] = p
+		return nil
+	}
+}
+
+// checkSeats checks every seat is for a colour that's playing, and that
+// nobody has two seats.
+func (b Board) checkSeats() error {
+	ids := map[string]bool{}
+	for c, p := range b.seats {
+		if !b.isPlaying(colourToPlayer(c)) {
+			return fmt.Errorf("can't seat %q as %v, they aren't playing", p.ID, colourToPlayer(c))
+		}
+		if ids[p.ID] {
+			return fmt.Errorf("player %q has more than one seat", p.ID)
+		}
+		ids[p.ID] = true
+	}
+	return nil
+}
+
+// Seat returns the player sitting at colour, if anyone is.
+func (b Board) Seat(colour string) (Player, bool) {
+	p, ok := b.seats[playerToColour(colour)]
+	return p, ok
+}
+
+// ColourOf returns the colour the player with the given ID is playing.
+func (b Board) ColourOf(id string) (string, bool) {
+	for c, p := range b.seats {
+		if p.ID == id {
+			return colourToPlayer(c), true
+		}
+	}
+	return "", false
+}
+
+// Join sits p at the first free colour, in turn order, of the game with
+// the given code, returning the colour. It fails with ErrGameNotFound if
+// the code isn't this game's, and ErrGameFull if every seat is taken.
+// Joining again returns the colour the player already has.
+func (b *Board) Join(code string, p Player) (string, error) {
+	if code != b.Code() {
+		return "", fmt.Errorf("can't join %q: %w", code, ErrGameNotFound)
+	}
+	if p.ID == "" {
+		return "", fmt.Errorf("can't join %q, the player has no ID", code)
+	}
+	if colour, ok := b.ColourOf(p.ID); ok {
+		return colour, nil
+	}
+	if b.GameOver() {
+		return "", fmt.Errorf("can't join %q, %w", code, ErrGameOver)
+	}
+
+	for _, c := range b.players() {
+		if _, taken := b.seats[c]; taken {
+			continue
+		}
+		if b.seats == nil {
+			b.seats = map[rune]Player{}
+		}
+		b.seats[c] = p
+		return colourToPlayer(c), nil
+	}
+	return "", fmt.Errorf("can't join %q: %w", code, ErrGameFull)
+}
+
+// Nigiri seats two players at random, using the board's random source,
+// the way players guess stones to decide who takes black. It's only for
+// even two player games that haven't started and have no one seated.
+func (b *Board) Nigiri(p1, p2 Player) error {
+	switch {
+	case len(b.players()) != 2:
+		return fmt.Errorf("nigiri is only for two player games")
+	case b.handicap > 0:
+		return fmt.Errorf("nigiri is only for even games; black takes the handicap")
+	case len(b.seats) > 0:
+		return fmt.Errorf("can't nigiri once players are seated")
+	case len(b.moves) > 0:
+		return fmt.Errorf("can't nigiri once the game has started")
+	case p1.ID == "" || p2.ID == "":
+		return fmt.Errorf("can't nigiri, a player has no ID")
+	case p1.ID == p2.ID:
+		return fmt.Errorf("can't nigiri, %q can't play themselves", p1.ID)
+	}
+
+	if b.rand.Intn(2) == 1 {
+		p1, p2 = p2, p1
+	}
+	b.seats = map[rune]Player{blackPiece: p1, whitePiece: p2}
+	return nil
+}
+
+// checkTurn returns ErrNotYourTurn unless the player with the given ID
+// is seated at the colour whose turn it is.
+func (b Board) checkTurn(id string) error {
+	colour, ok := b.ColourOf(id)
+	if !ok {
+		return fmt.Errorf("player %q isn't seated in this game", id)
+	}
+	if colour != b.currentPlayer {
+		return fmt.Errorf("%q is %v, %w, it's %v's turn", id, colour, ErrNotYourTurn, b.currentPlayer)
+	}
+	return nil
+}
+
+// PlayAs is Play, for the player with the given ID, who has to be
+// seated at the colour whose turn it is.
+func (b *Board) PlayAs(id, input string) (Result, error) {
+	if err := b.checkTurn(id); err != nil {
+		return Result{}, &MoveError{Player: b.currentPlayer, Name: input, Err: err}
+	}
+	return b.Play(input)
+}
+
+// PlaceAs is Place, for the player with the given ID, who has to be
+// seated at the colour whose turn it is.
+func (b *Board) PlaceAs(id string, p Point) (Result, error) {
+	if err := b.checkTurn(id); err != nil {
+		return Result{}, b.moveError(p, err)
+	}
+	return b.Place(p)
+}
+
+// PassAs is Pass, for the player with the given ID, who has to be seated
+// at the colour whose turn it is.
+func (b *Board) PassAs(id string) (Result, error) {
+	if err := b.checkTurn(id); err != nil {
+		return Result{}, fmt.Errorf("can't pass, %w", err)
+	}
+	return b.Pass()
+}
+
+// ResignAs resigns the game for the player with the given ID, whose turn
+// it doesn't have to be.
+func (b *Board) ResignAs(id string) error {
+	colour, ok := b.ColourOf(id)
+	if !ok {
+		return fmt.Errorf("player %q isn't seated in this game", id)
+	}
+	return b.Resign(colour)
+}
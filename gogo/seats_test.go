@@ -0,0 +1,205 @@
+package gogo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	alice = Player{ID: "alice", Name: "Alice", Rank: "3k"}
+	bob   = Player{ID: "bob", Name: "Bob", Rank: "1d"}
+	carol = Player{ID: "carol", Name: "Carol"}
+)
+
+func TestJoin(t *testing.T) {
+	board, err := NewBoard(9)
+	require.NoError(t, err)
+	code := board.Code()
+
+	_, err = board.Join(code+"X", alice)
+	assert.ErrorIs(t, err, ErrGameNotFound)
+	_, err = board.Join(code, Player{Name: "nobody"})
+	assert.Error(t, err)
+
+	colour, err := board.Join(code, alice)
+	require.NoError(t, err)
+	assert.Equal(t, blackPlayer, colour)
+
+	// joining twice gets the same seat
+	colour, err = board.Join(code, alice)
+	require.NoError(t, err)
+	assert.Equal(t, blackPlayer, colour)
+
+	colour, err = board.Join(code, bob)
+	require.NoError(t, err)
+	assert.Equal(t, whitePlayer, colour)
+
+	_, err = board.Join(code, carol)
+	assert.ErrorIs(t, err, ErrGameFull)
+
+	seat, ok := board.Seat(whitePlayer)
+	require.True(t, ok)
+	assert.Equal(t, bob, seat)
+	colour, ok = board.ColourOf("alice")
+	assert.True(t, ok)
+	assert.Equal(t, blackPlayer, colour)
+	_, ok = board.ColourOf("carol")
+	assert.False(t, ok)
+}
+
+func TestWithSeat(t *testing.T) {
+	tests := []struct {
+		opts      []Option
+		expect    map[string]Player
+		expectErr bool
+	}{
+		{
+			opts:   []Option{WithSeat(whitePlayer, alice)},
+			expect: map[string]Player{whitePlayer: alice},
+		},
+		{
+			opts:   []Option{WithSeat(redPlayer, carol), WithPlayers(3)},
+			expect: map[string]Player{redPlayer: carol},
+		},
+		{opts: []Option{WithSeat(redPlayer, carol)}, expectErr: true},
+		{opts: []Option{WithSeat("purple", carol)}, expectErr: true},
+		{opts: []Option{WithSeat(blackPlayer, Player{Name: "no id"})}, expectErr: true},
+		{opts: []Option{WithSeat(blackPlayer, alice), WithSeat(whitePlayer, alice)}, expectErr: true},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			board, err := NewBoard(9, tt.opts...)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for colour, p := range tt.expect {
+				got, ok := board.Seat(colour)
+				assert.True(t, ok)
+				assert.Equal(t, p, got)
+			}
+		})
+	}
+}
+
+func TestNigiri(t *testing.T) {
+	blacks := map[string]int{}
+	for seed := int64(0); seed < 20; seed++ {
+		board, err := NewBoard(9)
+		require.NoError(t, err)
+		board.rand = rand.New(rand.NewSource(seed))
+
+		require.NoError(t, board.Nigiri(alice, bob))
+		black, ok := board.Seat(blackPlayer)
+		require.True(t, ok)
+		white, ok := board.Seat(whitePlayer)
+		require.True(t, ok)
+		assert.NotEqual(t, black, white)
+		blacks[black.ID]++
+
+		// the same seed gives the same colours
+		again, err := NewBoard(9)
+		require.NoError(t, err)
+		again.rand = rand.New(rand.NewSource(seed))
+		require.NoError(t, again.Nigiri(alice, bob))
+		againBlack, _ := again.Seat(blackPlayer)
+		assert.Equal(t, black, againBlack)
+
+		assert.Error(t, board.Nigiri(alice, bob), "already seated")
+	}
+	assert.Len(t, blacks, 2, "both players get black sometimes")
+
+	handicap, err := NewBoard(9, WithHandicap(2))
+	require.NoError(t, err)
+	assert.Error(t, handicap.Nigiri(alice, bob))
+
+	three, err := NewBoard(9, WithPlayers(3))
+	require.NoError(t, err)
+	assert.Error(t, three.Nigiri(alice, bob))
+
+	started, err := NewBoard(9)
+	require.NoError(t, err)
+	require.NoError(t, play(&started, []string{"A1"}))
+	assert.Error(t, started.Nigiri(alice, bob))
+
+	same, err := NewBoard(9)
+	require.NoError(t, err)
+	assert.Error(t, same.Nigiri(alice, alice))
+}
+
+func TestPlayAs(t *testing.T) {
+	board, err := NewBoard(9, WithSeat(blackPlayer, alice), WithSeat(whitePlayer, bob))
+	require.NoError(t, err)
+
+	_, err = board.PlayAs("bob", "A1")
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+	var moveErr *MoveError
+	require.ErrorAs(t, err, &moveErr)
+	assert.Equal(t, "A1", moveErr.Name)
+
+	_, err = board.PlayAs("carol", "A1")
+	assert.Error(t, err)
+
+	_, err = board.PlayAs("alice", "A1")
+	require.NoError(t, err)
+	_, err = board.PlaceAs("alice", Point{X: 1, Y: 1})
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+	_, err = board.PlaceAs("bob", Point{X: 1, Y: 1})
+	require.NoError(t, err)
+
+	_, err = board.PassAs("bob")
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+	_, err = board.PassAs("alice")
+	require.NoError(t, err)
+
+	// resigning doesn't need it to be your turn
+	require.NoError(t, board.ResignAs("alice"))
+	res, ok := board.GameResult()
+	require.True(t, ok)
+	assert.Equal(t, "W+R", res.String())
+}
+
+func TestSeatsAreSaved(t *testing.T) {
+	board, err := NewBoard(9, WithSeat(blackPlayer, alice))
+	require.NoError(t, err)
+	_, err = board.Join(board.Code(), bob)
+	require.NoError(t, err)
+	require.NoError(t, play(&board, []string{"C3", "D4", "undo"}))
+
+	// undo keeps the seats
+	seat, ok := board.Seat(whitePlayer)
+	require.True(t, ok)
+	assert.Equal(t, bob, seat)
+
+	data, err := json.Marshal(&board)
+	require.NoError(t, err)
+	var fromJSON Board
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	seat, _ = fromJSON.Seat(blackPlayer)
+	assert.Equal(t, alice, seat)
+
+	store := NewMemoryStore()
+	require.NoError(t, store.Save(&board))
+	loaded, err := store.Load(board.Code())
+	require.NoError(t, err)
+	seat, _ = loaded.Seat(whitePlayer)
+	assert.Equal(t, bob, seat)
+
+	// SGF only has names and ranks
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, board.EncodeSGF(buf))
+	assert.Contains(t, buf.String(), "PB[Alice]BR[3k]PW[Bob]WR[1d]")
+	fromSGF, err := DecodeSGF(buf)
+	require.NoError(t, err)
+	seat, _ = fromSGF.Seat(whitePlayer)
+	assert.Equal(t, Player{ID: "Bob", Name: "Bob", Rank: "1d"}, seat)
+}
@@ -0,0 +1,479 @@
+package gogo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// sgfNode is a single node of an SGF game tree.
+type sgfNode struct {
+	props    map[string][]string
+	children []*sgfNode
+}
+
+// get returns the first value of the property, if it's set.
+func (n *sgfNode) get(prop string) (string, bool) {
+	v, ok := n.props[prop]
+	if !ok || len(v) == 0 {
+		return "", false
+	}
+	return v[0], true
+}
+
+// EncodeSGF writes the game as an SGF (FF[4]) record. The board code is
+// stored as the game name, GN, and rectangular boards use SZ[w:h]. The
+// ruleset presets are written as RU, along with the komi, KM, any
+// handicap stones, HA and AB, the result of a finished game, RE, and the
+// names and ranks of the seated players, PB, PW, BR and WR. SGF has no
+// way to record the board's topology, so only the moves are written.
+// Games played by any other rules, like AtariGo, Gomoku or a custom
+// Ruleset, can't be encoded, as they'd load as a game of Go.
+func (b Board) EncodeSGF(w io.Writer) error {
+	root, err := b.sgfRoot()
+	if err != nil {
+		return err
+	}
+
+	sb := bytes.NewBuffer(nil)
+	sb.WriteString("(;" + root)
+	for _, m := range b.moves {
+		pos := ""
+		if m.idx != passMove {
+			pos = b.idxToPoint(m.idx).SGF(b.height)
+		}
+		sb.WriteString(fmt.Sprintf(";%c[%s]", m.piece, pos))
+	}
+	sb.WriteString(")\n")
+
+	_, err = w.Write(sb.Bytes())
+	return err
+}
+
+// sgfRoot returns the properties of the root node that EncodeSGF writes
+// for the game.
+func (b Board) sgfRoot() (string, error) {
+	if b.board == nil {
+		return "", fmt.Errorf("can't encode an invalid board")
+	}
+	if len(b.players()) > 2 {
+		return "", fmt.Errorf("can't encode a game with more than two players")
+	}
+
+	// SGF only has the presets' RU values, and a game without one loads
+	// with the DefaultRules, so other rules, like Atari Go or Gomoku,
+	// would come back as a different game
+	r, ok := b.Rules().(Ruleset)
+	if !ok {
+		return "", fmt.Errorf("can't encode a game of %v as SGF", b.Rules().Name())
+	}
+	ru := ""
+	for name, rs := range sgfRulesets {
+		if r == rs {
+			ru = name
+		}
+	}
+	if def, ok := DefaultRules.(Ruleset); ru == "" && (!ok || r != def) {
+		return "", fmt.Errorf("can't encode a game with %v rules as SGF", r.Name())
+	}
+
+	size := fmt.Sprintf("%d", b.width)
+	if b.width != b.height {
+		size = fmt.Sprintf("%d:%d", b.width, b.height)
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("FF[4]GM[1]CA[UTF-8]SZ[%s]", size))
+	if b.code != "" {
+		sb.WriteString(fmt.Sprintf("GN[%s]", sgfEscape(b.code)))
+	}
+	if ru != "" {
+		sb.WriteString(fmt.Sprintf("RU[%s]", ru))
+	}
+	if komi := b.Komi(); komi != 0 || b.komi != nil {
+		sb.WriteString(fmt.Sprintf("KM[%s]", strconv.FormatFloat(komi, 'f', -1, 64)))
+	}
+	if b.handicap > 0 {
+		sb.WriteString(fmt.Sprintf("HA[%d]AB", b.handicap))
+		points, err := handicapPoints(b.handicap, b.width, b.height)
+		if err != nil {
+			return "", err
+		}
+		for _, p := range points {
+			sb.WriteString(fmt.Sprintf("[%s]", p.SGF(b.height)))
+		}
+	}
+	if res, ok := b.GameResult(); ok {
+		sb.WriteString(fmt.Sprintf("RE[%s]", res))
+	}
+	for _, prop := range sgfSeats {
+		p, ok := b.seats[prop.colour]
+		if !ok {
+			continue
+		}
+		name := p.Name
+		if name == "" {
+			name = p.ID
+		}
+		sb.WriteString(fmt.Sprintf("%s[%s]", prop.name, sgfEscape(name)))
+		if p.Rank != "" {
+			sb.WriteString(fmt.Sprintf("%s[%s]", prop.rank, sgfEscape(p.Rank)))
+		}
+	}
+	return sb.String(), nil
+}
+
+// sgfSeats are the properties that hold the name and rank of the
+// players at each colour.
+var sgfSeats = []struct {
+	colour     rune
+	name, rank string
+}{
+	{colour: blackPiece, name: "PB", rank: "BR"},
+	{colour: whitePiece, name: "PW", rank: "WR"},
+}
+
+// sgfRulesets maps the RU values EncodeSGF writes to the presets.
+var sgfRulesets = map[string]Ruleset{
+	"Japanese":     Japanese,
+	"Chinese":      Chinese,
+	"AGA":          AGA,
+	"NZ":           NewZealand,
+	"Tromp-Taylor": TrompTaylor,
+	"GOE":          Ing,
+}
+
+// DecodeSGF reads the main line of the first game in an SGF record and
+// plays it out on a new board. An empty move, or "tt" on boards up to
+// 19x19, is a pass. RU, KM and HA are read, and AB is only allowed to
+// place handicap stones where WithHandicap would. A result of RE with a
+// resignation, timeout or forfeit ends the game that way once the moves
+// have been played. The players named by PB and PW, with their ranks from
+// BR and WR, are seated using their names as their IDs.
+func DecodeSGF(r io.Reader) (Board, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Board{}, err
+	}
+
+	root, err := parseSGF(string(data))
+	if err != nil {
+		return Board{}, err
+	}
+
+	width, height := 19, 19
+	if sz, ok := root.get("SZ"); ok {
+		width, height, err = parseSGFSize(sz)
+		if err != nil {
+			return Board{}, err
+		}
+	}
+
+	var opts []Option
+	if ru, ok := root.get("RU"); ok {
+		for name, rs := range sgfRulesets {
+			if strings.EqualFold(name, ru) {
+				opts = append(opts, WithRules(rs))
+			}
+		}
+	}
+	if ha, ok := root.get("HA"); ok {
+		n, err := strconv.Atoi(ha)
+		if err != nil {
+			return Board{}, fmt.Errorf("invalid handicap %q", ha)
+		}
+		opts = append(opts, WithHandicap(n))
+	}
+
+	b, err := NewRectBoard(width, height, opts...)
+	if err != nil {
+		return Board{}, err
+	}
+	b.code, _ = root.get("GN")
+	DefaultCodes.use(b.code)
+	for _, prop := range sgfSeats {
+		name, ok := root.get(prop.name)
+		if !ok || name == "" {
+			continue
+		}
+		if b.seats == nil {
+			b.seats = map[rune]Player{}
+		}
+		rank, _ := root.get(prop.rank)
+		b.seats[prop.colour] = Player{ID: name, Name: name, Rank: rank}
+	}
+
+	if km, ok := root.get("KM"); ok {
+		komi, err := strconv.ParseFloat(km, 64)
+		if err != nil {
+			return Board{}, fmt.Errorf("invalid komi %q", km)
+		}
+		if komi != b.Komi() {
+			b.komi = &komi
+		}
+	}
+
+	if err := checkSGFHandicap(root, b); err != nil {
+		return Board{}, err
+	}
+
+	for node, num := root, 1; node != nil; node = firstChild(node) {
+		for _, prop := range []string{"AB", "AW", "AE"} {
+			if _, ok := node.props[prop]; ok && (node != root || prop != "AB") {
+				return Board{}, fmt.Errorf("setup property %v isn't supported", prop)
+			}
+		}
+
+		for _, colour := range []rune{blackPiece, whitePiece} {
+			pos, ok := node.get(string(colour))
+			if !ok {
+				continue
+			}
+			if colour != b.nextPiece {
+				return Board{}, fmt.Errorf("move %d is %c, %w, it's %v's turn", num, colour, ErrNotYourTurn, b.currentPlayer)
+			}
+
+			num++
+			if pos == "" || (pos == "tt" && b.width <= 19 && b.height <= 19) {
+				if _, err := b.Pass(); err != nil {
+					return Board{}, fmt.Errorf("move %d: %w", num-1, err)
+				}
+				continue
+			}
+
+			p, err := ParseSGF(pos, b.height)
+			if err != nil {
+				return Board{}, fmt.Errorf("move %d: %w", num-1, err)
+			}
+			if _, err := b.Place(p); err != nil {
+				return Board{}, fmt.Errorf("move %d: %w", num-1, err)
+			}
+		}
+	}
+
+	if re, ok := root.get("RE"); ok {
+		// results like "Void" or "?" are left for the moves to decide
+		if res, err := ParseGameResult(re); err == nil {
+			if reason, ok := sgfForfeits[res.Reason]; ok {
+				if err := b.forfeit(reason, res.Loser); err != nil {
+					return Board{}, fmt.Errorf("result %q: %w", re, err)
+				}
+			}
+		}
+	}
+
+	return b, nil
+}
+
+// sgfForfeits maps the results DecodeSGF replays to the event that ends
+// the game that way.
+var sgfForfeits = map[ResultReason]EventType{
+	ReasonResign:  EventResign,
+	ReasonTime:    EventTimeout,
+	ReasonForfeit: EventForfeit,
+}
+
+// checkSGFHandicap checks that the AB property in the root node places
+// exactly the handicap stones that are already on the board.
+func checkSGFHandicap(root *sgfNode, b Board) error {
+	ab := root.props["AB"]
+	if len(ab) == 0 {
+		return nil
+	}
+	if b.handicap == 0 {
+		return fmt.Errorf("setup property AB is only supported for handicap stones")
+	}
+	if len(ab) != b.handicap {
+		return fmt.Errorf("expected %v handicap stones, got %v", b.handicap, len(ab))
+	}
+	for _, pos := range ab {
+		p, err := ParseSGF(pos, b.height)
+		if err != nil {
+			return err
+		}
+		idx, err := b.pointToIdx(p)
+		if err != nil {
+			return err
+		}
+		if b.board[idx] != blackPiece {
+			return fmt.Errorf("handicap stone %q isn't on a handicap point", pos)
+		}
+	}
+	return nil
+}
+
+// parseSGFSize parses the SZ property, which is either a single number
+// for square boards or "width:height".
+func parseSGFSize(sz string) (int, int, error) {
+	bits := strings.Split(sz, ":")
+	if len(bits) > 2 {
+		return 0, 0, fmt.Errorf("invalid board size %q", sz)
+	}
+
+	width, err := strconv.Atoi(bits[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid board size %q", sz)
+	}
+	height := width
+	if len(bits) == 2 {
+		if height, err = strconv.Atoi(bits[1]); err != nil {
+			return 0, 0, fmt.Errorf("invalid board size %q", sz)
+		}
+	}
+	return width, height, nil
+}
+
+// firstChild ...
+func firstChild(n *sgfNode) *sgfNode {
+	if len(n.children) == 0 {
+		return nil
+	}
+	return n.children[0]
+}
+
+// sgfEscape ...
+func sgfEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "]", `\]`)
+}
+
+// sgfParser is a small recursive descent parser for the SGF grammar:
+//
+//	Collection = GameTree { GameTree }
+//	GameTree   = "(" Sequence { GameTree } ")"
+//	Sequence   = Node { Node }
+//	Node       = ";" { Property }
+//	Property   = PropIdent PropValue { PropValue }
+type sgfParser struct {
+	data string
+	pos  int
+}
+
+// parseSGF returns the root node of the first game tree in data.
+func parseSGF(data string) (*sgfNode, error) {
+	p := &sgfParser{data: data}
+	p.skipSpace()
+	return p.tree()
+}
+
+// tree ...
+func (p *sgfParser) tree() (*sgfNode, error) {
+	if !p.accept('(') {
+		return nil, p.errorf("expected '('")
+	}
+
+	var root, last *sgfNode
+	for p.skipSpace(); p.peek() == ';'; p.skipSpace() {
+		node, err := p.node()
+		if err != nil {
+			return nil, err
+		}
+		if root == nil {
+			root = node
+		} else {
+			last.children = append(last.children, node)
+		}
+		last = node
+	}
+	if root == nil {
+		return nil, p.errorf("expected ';'")
+	}
+
+	for p.peek() == '(' {
+		child, err := p.tree()
+		if err != nil {
+			return nil, err
+		}
+		last.children = append(last.children, child)
+		p.skipSpace()
+	}
+
+	if !p.accept(')') {
+		return nil, p.errorf("expected ')'")
+	}
+	return root, nil
+}
+
+// node ...
+func (p *sgfParser) node() (*sgfNode, error) {
+	p.accept(';')
+	node := &sgfNode{props: map[string][]string{}}
+
+	for {
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.data) && p.data[p.pos] >= 'A' && p.data[p.pos] <= 'Z' {
+			p.pos++
+		}
+		ident := p.data[start:p.pos]
+		if ident == "" {
+			return node, nil
+		}
+
+		p.skipSpace()
+		if p.peek() != '[' {
+			return nil, p.errorf("expected value for property %v", ident)
+		}
+		for p.peek() == '[' {
+			val, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			node.props[ident] = append(node.props[ident], val)
+			p.skipSpace()
+		}
+	}
+}
+
+// value ...
+func (p *sgfParser) value() (string, error) {
+	p.accept('[')
+	sb := strings.Builder{}
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '\\':
+			if p.pos < len(p.data) {
+				sb.WriteByte(p.data[p.pos])
+				p.pos++
+			}
+		case ']':
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated property value")
+}
+
+// peek ...
+func (p *sgfParser) peek() byte {
+	if p.pos >= len(p.data) {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+// accept ...
+func (p *sgfParser) accept(c byte) bool {
+	if p.peek() != c {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// skipSpace ...
+func (p *sgfParser) skipSpace() {
+	for p.pos < len(p.data) && strings.ContainsRune(" \t\r\n", rune(p.data[p.pos])) {
+		p.pos++
+	}
+}
+
+// errorf ...
+func (p *sgfParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("sgf: %v at offset %d", fmt.Sprintf(format, args...), p.pos)
+}
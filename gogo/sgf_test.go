@@ -0,0 +1,146 @@
+package gogo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeSGF(t *testing.T) {
+	tests := []struct {
+		size   int
+		inputs []string
+		expect string
+	}{
+		{size: 9, expect: "(;FF[4]GM[1]CA[UTF-8]SZ[9]GN[TEST])\n"},
+		{
+			size:   4,
+			inputs: []string{"A1", "B2", "D4"},
+			expect: "(;FF[4]GM[1]CA[UTF-8]SZ[4]GN[TEST];B[ad];W[bc];B[da])\n",
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v inputs %v", i, strings.Join(tt.inputs, "_")), func(t *testing.T) {
+			board, err := NewBoard(tt.size)
+			require.NoError(t, err)
+			board.code = "TEST"
+
+			for _, in := range tt.inputs {
+				_, err := board.Play(in)
+				require.NoError(t, err)
+			}
+
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, board.EncodeSGF(buf))
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+}
+
+func TestEncodeSGFRules(t *testing.T) {
+	tests := []struct {
+		rules     Rules
+		expectErr bool
+	}{
+		{rules: DefaultRules},
+		{rules: Japanese},
+		{rules: Ing},
+		// these would load as a game of Go
+		{rules: AtariGo, expectErr: true},
+		{rules: FreestyleGomoku, expectErr: true},
+		{rules: StandardGomoku, expectErr: true},
+		{rules: Ruleset{Ko: PositionalSuperko, Scoring: TerritoryScoring}, expectErr: true},
+		{rules: noTengen{DefaultRuleset}, expectErr: true},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v rules %v", i, tt.rules.Name()), func(t *testing.T) {
+			board, err := NewBoard(9, WithRules(tt.rules))
+			require.NoError(t, err)
+			require.NoError(t, play(&board, []string{"D4", "C3"}))
+
+			store, err := NewFileStore(t.TempDir(), FormatSGF)
+			require.NoError(t, err)
+			buf := bytes.NewBuffer(nil)
+			if tt.expectErr {
+				assert.Error(t, board.EncodeSGF(buf))
+				assert.Error(t, store.Save(&board))
+				return
+			}
+
+			require.NoError(t, board.EncodeSGF(buf))
+			decoded, err := DecodeSGF(buf)
+			require.NoError(t, err)
+			assert.Equal(t, tt.rules.Name(), decoded.Rules().Name())
+
+			require.NoError(t, store.Save(&board))
+			loaded, err := store.Load(board.Code())
+			require.NoError(t, err)
+			assert.Equal(t, tt.rules.Name(), loaded.Rules().Name())
+		})
+	}
+}
+
+func TestDecodeSGF(t *testing.T) {
+	tests := []struct {
+		input  string
+		valid  bool
+		expect string
+		code   string
+	}{
+		{input: ""},
+		{input: "(;SZ[3])"},
+		{input: "(;SZ[4];B[aa]"},
+		{input: "(;SZ[4];W[aa])"},
+		{input: "(;SZ[4];B[ee])"},
+		{input: "(;SZ[4]AB[aa])"},
+		{input: "(;SZ[4];B[aa];W[aa])"},
+		{
+			input: "(;FF[4]GM[1]SZ[4]GN[AB\\]C];B[ad];W[bc])",
+			valid: true,
+			code:  "AB]C",
+			expect: `4 X X X X
+3 X X X X
+2 X W X X
+1 B X X X
+  A B C D`,
+		},
+		// only the main line is played
+		{
+			input: `(;SZ[4]
+  ;B[ad]
+  (;W[bc];B[cc])
+  (;W[dd]))`,
+			valid: true,
+			expect: `4 X X X X
+3 X X X X
+2 X W B X
+1 B X X X
+  A B C D`,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			board, err := DecodeSGF(strings.NewReader(tt.input))
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, board.String())
+			if tt.code != "" {
+				assert.Equal(t, tt.code, board.Code())
+			}
+		})
+	}
+}
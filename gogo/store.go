@@ -0,0 +1,416 @@
+package gogo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrGameNotFound is returned by a Store when there's no game saved with
+// the requested code.
+var ErrGameNotFound = errors.New("game not found")
+
+// Store saves and loads games by their Code(), so that games survive a
+// restart. Loading a game replays its event log, so the loaded board is
+// in exactly the same state as the one that was saved. Games played by
+// rules other than a Ruleset and the built in variants can't be saved,
+// as they couldn't be loaded again. The SGF format only keeps the moves
+// played, not undos, dead stones, how the game ended, queued conditional
+// moves, correspondence clocks or a starting position loaded from JSON,
+// and can't save boards that aren't a Plane, have more than two players,
+// or are played by rules other than DefaultRules and the Ruleset
+// presets.
+type Store interface {
+	// Save stores the game, replacing any game saved with the same code.
+	Save(b *Board) error
+	// Load returns the game saved with the given code, or
+	// ErrGameNotFound.
+	Load(code string) (Board, error)
+	// Delete removes the game saved with the given code, or returns
+	// ErrGameNotFound.
+	Delete(code string) error
+	// List returns the codes of every saved game, sorted.
+	List() ([]string, error)
+}
+
+// gameRecord is everything needed to rebuild a game.
+// Start is only set for games that didn't begin on an empty board.
+type gameRecord struct {
+	Code   string `json:"code"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	settings
+	Start  *Board  `json:"start,omitempty"`
+	Events []Event `json:"events"`
+	// Conditional is the moves each player has queued, which are put
+	// back once the events have been replayed.
+	Conditional map[string][]ConditionalMove `json:"conditional,omitempty"`
+	// Clock is the game's correspondence clock, if it has one.
+	Clock *clockRecord `json:"clock,omitempty"`
+}
+
+// settings are the options a board was created with, as they're saved.
+// Each is left out when it's the default.
+type settings struct {
+	Topology string            `json:"topology,omitempty"`
+	Players  int               `json:"players,omitempty"`
+	Variant  string            `json:"variant,omitempty"`
+	Ruleset  *Ruleset          `json:"ruleset,omitempty"`
+	Komi     *float64          `json:"komi,omitempty"`
+	Handicap int               `json:"handicap,omitempty"`
+	Seats    map[string]Player `json:"seats,omitempty"`
+}
+
+// settings ...
+func (b Board) settings() settings {
+	s := settings{Variant: b.variant(), Komi: b.komi, Handicap: b.handicap}
+	if r, ok := b.Rules().(Ruleset); ok && r.name == "" {
+		s.Ruleset = &r
+	}
+	if t := b.Topology(); t != Plane {
+		s.Topology = t.Name()
+	}
+	if n := len(b.players()); n > 2 {
+		s.Players = n
+	}
+	for c, p := range b.seats {
+		if s.Seats == nil {
+			s.Seats = map[string]Player{}
+		}
+		s.Seats[colourToPlayer(c)] = p
+	}
+	return s
+}
+
+// options returns the options that recreate a board from its saved
+// settings.
+func (s settings) options() ([]Option, error) {
+	var opts []Option
+	if s.Ruleset != nil {
+		opts = append(opts, WithRules(*s.Ruleset))
+	} else {
+		var err error
+		if opts, err = variantOptions(s.Variant); err != nil {
+			return nil, err
+		}
+	}
+	if s.Topology != "" {
+		t, err := topologyNamed(s.Topology)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTopology(t))
+	}
+	if s.Players != 0 {
+		opts = append(opts, WithPlayers(s.Players))
+	}
+	if s.Komi != nil {
+		opts = append(opts, WithKomi(*s.Komi))
+	}
+	if s.Handicap != 0 {
+		opts = append(opts, WithHandicap(s.Handicap))
+	}
+	for colour, p := range s.Seats {
+		opts = append(opts, WithSeat(colour, p))
+	}
+	return opts, nil
+}
+
+// record returns what's saved for the game, or an error if its rules
+// couldn't be loaded again.
+func (b *Board) record() (gameRecord, error) {
+	rec := gameRecord{Code: b.Code(), Width: b.width, Height: b.height, settings: b.settings(), Events: b.Events(), Clock: b.clock}
+	if rec.Ruleset == nil {
+		if _, err := variantOptions(rec.Variant); err != nil {
+			return gameRecord{}, fmt.Errorf("can't save game %q, its rules can't be loaded again: %w", rec.Code, err)
+		}
+	}
+	if b.start != nil {
+		start := b.replay(0)
+		rec.Start = &start
+	}
+	for c, moves := range b.conditional {
+		if rec.Conditional == nil {
+			rec.Conditional = map[string][]ConditionalMove{}
+		}
+		rec.Conditional[colourToPlayer(c)] = copyConditional(moves)
+	}
+	return rec, nil
+}
+
+// board rebuilds the game by replaying the event log on a new board.
+func (r gameRecord) board() (Board, error) {
+	var b Board
+	var err error
+	if r.Start != nil {
+		b, err = rebuild(*r.Start, r.Events, -1)
+	} else {
+		var opts []Option
+		if opts, err = r.settings.options(); err != nil {
+			return Board{}, fmt.Errorf("unable to rebuild game %q: %w", r.Code, err)
+		}
+		b, err = RebuildRect(r.Width, r.Height, r.Events, opts...)
+	}
+	if err != nil {
+		return Board{}, fmt.Errorf("unable to rebuild game %q: %w", r.Code, err)
+	}
+	b.code = r.Code
+	DefaultCodes.use(b.code)
+	b.clock = r.Clock
+	for player, moves := range r.Conditional {
+		if !b.isPlaying(player) {
+			return Board{}, fmt.Errorf("unable to rebuild game %q, %q has queued moves but isn't playing", r.Code, player)
+		}
+		if b.conditional == nil {
+			b.conditional = map[rune][]ConditionalMove{}
+		}
+		b.conditional[playerToColour(player)] = moves
+	}
+	return b, nil
+}
+
+// MemoryStore keeps games in memory. It's safe for concurrent use.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	games map[string]gameRecord
+}
+
+// NewMemoryStore ...
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{games: map[string]gameRecord{}}
+}
+
+// Save ...
+func (s *MemoryStore) Save(b *Board) error {
+	if b.board == nil {
+		return fmt.Errorf("can't save an invalid board")
+	}
+	rec, err := b.record()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.games[rec.Code] = rec
+	return nil
+}
+
+// Load ...
+func (s *MemoryStore) Load(code string) (Board, error) {
+	s.mu.RLock()
+	rec, ok := s.games[code]
+	s.mu.RUnlock()
+
+	if !ok {
+		return Board{}, fmt.Errorf("unable to load %q: %w", code, ErrGameNotFound)
+	}
+	return rec.board()
+}
+
+// Delete removes the game, and Releases its code in DefaultCodes.
+func (s *MemoryStore) Delete(code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.games[code]; !ok {
+		return fmt.Errorf("unable to delete %q: %w", code, ErrGameNotFound)
+	}
+	delete(s.games, code)
+	DefaultCodes.Release(code)
+	return nil
+}
+
+// List ...
+func (s *MemoryStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	codes := make([]string, 0, len(s.games))
+	for c := range s.games {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+// FileFormat is the encoding a FileStore uses for each game.
+type FileFormat string
+
+const (
+	// FormatJSON stores each game as "<code>.json".
+	FormatJSON FileFormat = "json"
+	// FormatSGF stores each game as "<code>.sgf".
+	FormatSGF FileFormat = "sgf"
+)
+
+// FileStore keeps one file per game in a directory. Files are written to
+// a temporary file and renamed into place, so a crash never leaves a
+// half written game behind. Files starting with a dot, like the rating
+// history of ratings.FileStore, are left alone. It's safe for concurrent
+// use within a single process.
+type FileStore struct {
+	mu     sync.Mutex
+	dir    string
+	format FileFormat
+}
+
+// NewFileStore creates dir if needed, and returns a store that saves
+// games in it using the given format.
+func NewFileStore(dir string, format FileFormat) (*FileStore, error) {
+	if format != FormatJSON && format != FormatSGF {
+		return nil, fmt.Errorf("unknown file format %q", format)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create store directory: %w", err)
+	}
+	s := &FileStore{dir: dir, format: format}
+	// new boards mustn't be given the code of a game that's already saved
+	if err := DefaultCodes.ReserveStored(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// maxFileCodeLen is the longest code a FileStore will use as a file name.
+const maxFileCodeLen int = 64
+
+// path returns the file the game with the given code is kept in. Codes
+// have to be ASCII letters and digits, so a code like "../x" can't
+// reach outside the store's directory.
+func (s *FileStore) path(code string) (string, error) {
+	if code == "" || len(code) > maxFileCodeLen {
+		return "", fmt.Errorf("invalid game code %q, it isn't 1 to %v characters long", code, maxFileCodeLen)
+	}
+	for _, c := range code {
+		if !isCodeChar(c) {
+			return "", fmt.Errorf("invalid game code %q, %q isn't a letter or digit", code, c)
+		}
+	}
+	return filepath.Join(s.dir, code+"."+string(s.format)), nil
+}
+
+// Save ...
+func (s *FileStore) Save(b *Board) error {
+	if b.board == nil {
+		return fmt.Errorf("can't save an invalid board")
+	}
+	path, err := s.path(b.Code())
+	if err != nil {
+		return fmt.Errorf("unable to save: %w", err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	switch s.format {
+	case FormatJSON:
+		rec, err := b.record()
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(buf).Encode(rec); err != nil {
+			return err
+		}
+	case FormatSGF:
+		if t := b.Topology(); t != Plane {
+			return fmt.Errorf("can't save a %v board as SGF", t.Name())
+		}
+		if err := b.EncodeSGF(buf); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-"+b.Code())
+	if err != nil {
+		return fmt.Errorf("unable to save %q: %w", b.Code(), err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to save %q: %w", b.Code(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to save %q: %w", b.Code(), err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load ...
+func (s *FileStore) Load(code string) (Board, error) {
+	path, err := s.path(code)
+	if err != nil {
+		return Board{}, fmt.Errorf("unable to load: %w", err)
+	}
+
+	s.mu.Lock()
+	data, err := os.ReadFile(path)
+	s.mu.Unlock()
+
+	if errors.Is(err, os.ErrNotExist) {
+		return Board{}, fmt.Errorf("unable to load %q: %w", code, ErrGameNotFound)
+	}
+	if err != nil {
+		return Board{}, fmt.Errorf("unable to load %q: %w", code, err)
+	}
+
+	switch s.format {
+	case FormatSGF:
+		return DecodeSGF(bytes.NewReader(data))
+	default:
+		var rec gameRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return Board{}, fmt.Errorf("unable to load %q: %w", code, err)
+		}
+		return rec.board()
+	}
+}
+
+// Delete removes the game, and Releases its code in DefaultCodes.
+func (s *FileStore) Delete(code string) error {
+	path, err := s.path(code)
+	if err != nil {
+		return fmt.Errorf("unable to delete: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to delete %q: %w", code, ErrGameNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	DefaultCodes.Release(code)
+	return nil
+}
+
+// List ...
+func (s *FileStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := "." + string(s.format)
+	codes := []string{}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		codes = append(codes, strings.TrimSuffix(name, ext))
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
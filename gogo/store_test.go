@@ -0,0 +1,148 @@
+package gogo
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSaveAndLoadGames(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(_ *testing.T) Store { return NewMemoryStore() },
+		"json file": func(t *testing.T) Store {
+			s, err := NewFileStore(t.TempDir(), FormatJSON)
+			require.NoError(t, err)
+			return s
+		},
+		"sgf file": func(t *testing.T) Store {
+			s, err := NewFileStore(t.TempDir(), FormatSGF)
+			require.NoError(t, err)
+			return s
+		},
+	}
+
+	tests := []struct {
+		size   int
+		inputs []string
+	}{
+		{size: 9},
+		{size: 4, inputs: []string{"A1", "A2"}},
+		// includes a capture of B2
+		{size: 4, inputs: []string{"B1", "B2", "A2", "A3", "C2", "C3", "B3"}},
+		{size: 19, inputs: []string{"D4", "Q16", "S19", "A1"}},
+	}
+
+	for name, newStore := range stores {
+		for i, x := range tests {
+			tt := x
+			newStore := newStore
+			t.Run(fmt.Sprintf("%v test %v size %v", name, i, tt.size), func(t *testing.T) {
+				store := newStore(t)
+
+				board, err := NewBoard(tt.size)
+				require.NoError(t, err)
+				board.rand = rand.New(rand.NewSource(int64(i)))
+
+				for _, in := range tt.inputs {
+					_, err := board.Play(in)
+					require.NoError(t, err)
+				}
+
+				require.NoError(t, store.Save(&board))
+
+				codes, err := store.List()
+				require.NoError(t, err)
+				assert.Equal(t, []string{board.Code()}, codes)
+
+				loaded, err := store.Load(board.Code())
+				require.NoError(t, err)
+				assert.Equal(t, board.Code(), loaded.Code())
+				assert.Equal(t, board.String(), loaded.String())
+				assert.Equal(t, board.CurrentPlayer(), loaded.CurrentPlayer())
+				assert.Equal(t, board.MoveNumber(), loaded.MoveNumber())
+
+				expectBlack, expectWhite := board.Prisoners()
+				gotBlack, gotWhite := loaded.Prisoners()
+				assert.Equal(t, expectBlack, gotBlack)
+				assert.Equal(t, expectWhite, gotWhite)
+
+				// saving again overwrites the earlier copy
+				_, err = board.Play("D1")
+				require.NoError(t, err)
+				require.NoError(t, store.Save(&board))
+
+				loaded, err = store.Load(board.Code())
+				require.NoError(t, err)
+				assert.Equal(t, board.String(), loaded.String())
+
+				// deleting frees the code for another game
+				require.NoError(t, store.Delete(board.Code()))
+				assert.False(t, DefaultCodes.InUse(board.Code()))
+				_, err = store.Load(board.Code())
+				assert.ErrorIs(t, err, ErrGameNotFound)
+				assert.ErrorIs(t, store.Delete(board.Code()), ErrGameNotFound)
+			})
+		}
+	}
+}
+
+func TestFileStoreRejectsBadCodes(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "games"), FormatJSON)
+	require.NoError(t, err)
+
+	for _, code := range []string{"", "../x", "../../x", "a/b", "x.json", "ab cd", strings.Repeat("A", maxFileCodeLen+1)} {
+		t.Run(fmt.Sprintf("code %q", code), func(t *testing.T) {
+			board, err := NewBoard(9)
+			require.NoError(t, err)
+			board.code = code
+			if code != "" {
+				assert.Error(t, store.Save(&board))
+			}
+
+			_, err = store.Load(code)
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, ErrGameNotFound)
+			assert.Error(t, store.Delete(code))
+		})
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "x.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestStoreRefusesCustomRules(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(_ *testing.T) Store { return NewMemoryStore() },
+		"json file": func(t *testing.T) Store {
+			s, err := NewFileStore(t.TempDir(), FormatJSON)
+			require.NoError(t, err)
+			return s
+		},
+		"sgf file": func(t *testing.T) Store {
+			s, err := NewFileStore(t.TempDir(), FormatSGF)
+			require.NoError(t, err)
+			return s
+		},
+	}
+
+	for name, x := range stores {
+		newStore := x
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			board, err := NewBoard(9, WithRules(bannedPoints{Ruleset: DefaultRuleset}))
+			require.NoError(t, err)
+
+			assert.Error(t, store.Save(&board))
+			codes, err := store.List()
+			require.NoError(t, err)
+			assert.Empty(t, codes)
+		})
+	}
+}
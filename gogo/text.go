@@ -0,0 +1,181 @@
+package gogo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	// legacyLetters labels columns A..Z, including 'I'.
+	legacyLetters string = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// gtpLetters labels columns the way Go boards and GTP do, skipping
+	// 'I' so it can't be confused with 'J' or '1'.
+	gtpLetters string = "ABCDEFGHJKLMNOPQRSTUVWXYZ"
+
+	unicodeBlack rune = '●'
+	unicodeWhite rune = '○'
+	unicodeEmpty rune = '·'
+	hoshiMarker  rune = '+'
+
+	ansiReset string = "\x1b[0m"
+	ansiBlack string = "\x1b[1;30;43m"
+	ansiWhite string = "\x1b[1;97;43m"
+	ansiRed   string = "\x1b[1;31;43m"
+	ansiGreen string = "\x1b[1;32;43m"
+	ansiEmpty string = "\x1b[30;43m"
+)
+
+// TextOptions controls how Board.Text renders a board. The zero value
+// gives the same output as Board.String.
+type TextOptions struct {
+	// GTPLetters labels columns skipping 'I', as on a real board. Boards
+	// wider than 25 points fall back to A..Z. It only changes the
+	// labels: Play still counts 'I' as a column, so points read off
+	// them need ParseGTP and Place.
+	GTPLetters bool
+	// Unicode draws stones as '●' and '○' and empty points as '·'
+	// instead of 'B', 'W' and 'X'. Red and green stones are always
+	// drawn as 'R' and 'G'.
+	Unicode bool
+	// Hoshi marks empty star points with '+'.
+	Hoshi bool
+	// HighlightLast wraps the most recently played stone in brackets.
+	HighlightLast bool
+	// Colour adds ANSI escape codes for terminals.
+	Colour bool
+}
+
+// Text renders the board as text, with the highest row at the top and
+// column letters along the bottom.
+func (b Board) Text(opts TextOptions) string {
+	if b.board == nil {
+		return "Invalid Board"
+	}
+
+	sb := bytes.NewBuffer(nil)
+	labelWidth := len(fmt.Sprintf("%v", b.height))
+	stars := map[int]bool{}
+	if opts.Hoshi {
+		stars = b.starPoints()
+	}
+
+	last := -1
+	if opts.HighlightLast && len(b.moves) > 0 {
+		if m := b.moves[len(b.moves)-1]; m.idx != passMove && b.board[m.idx] == m.piece {
+			last = m.idx
+		}
+	}
+
+	for i := b.height; i >= 1; i-- {
+		sb.WriteString(fmt.Sprintf("%*d", labelWidth, i))
+
+		// the separator before each point, and one after the last point
+		seps := make([]string, b.width+1)
+		for j := range seps {
+			seps[j] = " "
+		}
+		seps[b.width] = ""
+
+		for j := 1; j <= b.width; j++ {
+			if b.coordsToIdx(i, j) == last {
+				seps[j-1], seps[j] = "(", ")"
+			}
+		}
+
+		for j := 1; j <= b.width; j++ {
+			idx := b.coordsToIdx(i, j)
+			sb.WriteString(seps[j-1])
+			sb.WriteString(opts.point(b.board[idx], stars[idx]))
+		}
+		sb.WriteString(seps[b.width])
+		sb.WriteString("\n")
+	}
+
+	letters := opts.letters(b.width)
+	sb.WriteString(strings.Repeat(" ", labelWidth))
+	for i := 0; i < b.width; i++ {
+		sb.WriteString(" ")
+		sb.WriteByte(letters[i])
+	}
+
+	return sb.String()
+}
+
+// letters returns the column lettering to use for a board of the given
+// width.
+func (o TextOptions) letters(width int) string {
+	if o.GTPLetters && width <= len(gtpLetters) {
+		return gtpLetters
+	}
+	return legacyLetters
+}
+
+// point renders a single point of the board.
+func (o TextOptions) point(p rune, star bool) string {
+	out, colour := p, ansiEmpty
+	switch p {
+	case blackPiece:
+		colour = ansiBlack
+		if o.Unicode {
+			out = unicodeBlack
+		}
+	case whitePiece:
+		colour = ansiWhite
+		if o.Unicode {
+			out = unicodeWhite
+		}
+	case redPiece:
+		colour = ansiRed
+	case greenPiece:
+		colour = ansiGreen
+	default:
+		if star {
+			out = hoshiMarker
+		} else if o.Unicode {
+			out = unicodeEmpty
+		}
+	}
+
+	if o.Colour {
+		return colour + string(out) + ansiReset
+	}
+	return string(out)
+}
+
+// starPoints returns the indexes of the hoshi on the board: the 3-3 (or
+// 4-4 on larger boards) points, the centre of boards with an odd width
+// and height, and the side points on sides of 15 and up.
+func (b Board) starPoints() map[int]bool {
+	stars := map[int]bool{}
+	cols, rows := starLines(b.width), starLines(b.height)
+
+	for _, r := range rows {
+		for _, c := range cols {
+			stars[b.coordsToIdx(r+1, c+1)] = true
+		}
+	}
+	if b.width%2 == 1 && b.height%2 == 1 {
+		stars[b.coordsToIdx(b.height/2+1, b.width/2+1)] = true
+	}
+
+	return stars
+}
+
+// starLines returns the 0-based lines along one side of the board that
+// have star points on them, not counting the centre point.
+func starLines(size int) []int {
+	if size < 7 {
+		return nil
+	}
+
+	edge := 2
+	if size >= 12 {
+		edge = 3
+	}
+	lines := []int{edge, size - 1 - edge}
+	if size%2 == 1 && size >= 15 {
+		lines = append(lines, size/2)
+	}
+	return lines
+}
@@ -0,0 +1,138 @@
+package gogo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBoardAsText(t *testing.T) {
+	tests := []struct {
+		size   int
+		inputs []string
+		opts   TextOptions
+		expect string
+	}{
+		// zero options matches String()
+		{
+			size:   4,
+			inputs: []string{"A1", "B2"},
+			expect: `4 X X X X
+3 X X X X
+2 X W X X
+1 B X X X
+  A B C D`,
+		},
+		{
+			size: 10,
+			opts: TextOptions{GTPLetters: true},
+			expect: `10 X X X X X X X X X X
+ 9 X X X X X X X X X X
+ 8 X X X X X X X X X X
+ 7 X X X X X X X X X X
+ 6 X X X X X X X X X X
+ 5 X X X X X X X X X X
+ 4 X X X X X X X X X X
+ 3 X X X X X X X X X X
+ 2 X X X X X X X X X X
+ 1 X X X X X X X X X X
+   A B C D E F G H J K`,
+		},
+		{
+			size:   4,
+			inputs: []string{"A1", "B2"},
+			opts:   TextOptions{Unicode: true},
+			expect: `4 · · · ·
+3 · · · ·
+2 · ○ · ·
+1 ● · · ·
+  A B C D`,
+		},
+		{
+			size:   9,
+			inputs: []string{"C3"},
+			opts:   TextOptions{Unicode: true, Hoshi: true, GTPLetters: true},
+			expect: `9 · · · · · · · · ·
+8 · · · · · · · · ·
+7 · · + · · · + · ·
+6 · · · · · · · · ·
+5 · · · · + · · · ·
+4 · · · · · · · · ·
+3 · · ● · · · + · ·
+2 · · · · · · · · ·
+1 · · · · · · · · ·
+  A B C D E F G H J`,
+		},
+		{
+			size:   4,
+			inputs: []string{"A1", "B2"},
+			opts:   TextOptions{HighlightLast: true},
+			expect: `4 X X X X
+3 X X X X
+2 X(W)X X
+1 B X X X
+  A B C D`,
+		},
+		{
+			size:   4,
+			inputs: []string{"D1"},
+			opts:   TextOptions{HighlightLast: true},
+			expect: `4 X X X X
+3 X X X X
+2 X X X X
+1 X X X(B)
+  A B C D`,
+		},
+		{
+			size:   4,
+			inputs: []string{"A1"},
+			opts:   TextOptions{Colour: true},
+			expect: "4 " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + "\n" +
+				"3 " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + "\n" +
+				"2 " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + "\n" +
+				"1 " + ansiBlack + "B" + ansiReset + " " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + " " + ansiEmpty + "X" + ansiReset + "\n" +
+				"  A B C D",
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v size %v opts %+v", i, tt.size, tt.opts), func(t *testing.T) {
+			board, err := NewBoard(tt.size)
+			require.NoError(t, err)
+
+			for _, in := range tt.inputs {
+				_, err := board.Play(in)
+				require.NoError(t, err)
+			}
+
+			got := board.Text(tt.opts)
+			assert.Equal(t, tt.expect, got, "expected board:\n%v\n\ngot board:\n%v\n", tt.expect, got)
+		})
+	}
+}
+
+func TestBoardStringIsQuiet(t *testing.T) {
+	board, err := NewBoard(9)
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	_ = board.String()
+
+	os.Stdout = stdout
+	require.NoError(t, w.Close())
+
+	buf := bytes.NewBuffer(nil)
+	_, err = io.Copy(buf, r)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,123 @@
+package gogo
+
+import "fmt"
+
+// Topology decides which points are next to each other, which is what
+// liberties, captures and scoring are all built on. The usual board is a
+// Plane; Torus and Cylinder join up the edges for variant games.
+type Topology interface {
+	// Name identifies the topology when a game is saved, so it can be
+	// loaded again.
+	Name() string
+	// Neighbours returns the points next to p on a board of the given
+	// size. Every point returned must be on the board.
+	Neighbours(p Point, width, height int) []Point
+}
+
+var (
+	// Plane is a normal board, where points on the edge have fewer
+	// neighbours.
+	Plane Topology = plane{}
+	// Torus joins the left edge to the right edge and the top edge to
+	// the bottom edge, so every point has four neighbours.
+	Torus Topology = torus{}
+	// Cylinder joins the left edge to the right edge, but leaves the top
+	// and bottom edges alone.
+	Cylinder Topology = cylinder{}
+)
+
+// WithTopology sets the topology of the board; boards are a Plane by
+// default.
+func WithTopology(t Topology) Option {
+	return func(b *Board) error {
+		if t != nil {
+			b.topology = t
+		}
+		return nil
+	}
+}
+
+// Topology returns the topology of the board.
+func (b Board) Topology() Topology {
+	if b.topology == nil {
+		return Plane
+	}
+	return b.topology
+}
+
+// topologyNamed returns the built in topology with the given name, for
+// loading saved games.
+func topologyNamed(name string) (Topology, error) {
+	for _, t := range []Topology{Plane, Torus, Cylinder} {
+		if t.Name() == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown topology %q", name)
+}
+
+// wraps reports whether the board's columns and rows join up at the
+// edges, for drawing boards that wrap.
+func (b Board) wraps() (bool, bool) {
+	var x, y bool
+	for _, n := range b.Topology().Neighbours(Point{}, b.width, b.height) {
+		if n.X == b.width-1 && n.Y == 0 {
+			x = true
+		}
+		if n.X == 0 && n.Y == b.height-1 {
+			y = true
+		}
+	}
+	return x, y
+}
+
+type plane struct{}
+
+// Name ...
+func (plane) Name() string { return "plane" }
+
+// Neighbours ...
+func (plane) Neighbours(p Point, width, height int) []Point {
+	return neighbours(p, width, height, false, false)
+}
+
+type torus struct{}
+
+// Name ...
+func (torus) Name() string { return "torus" }
+
+// Neighbours ...
+func (torus) Neighbours(p Point, width, height int) []Point {
+	return neighbours(p, width, height, true, true)
+}
+
+type cylinder struct{}
+
+// Name ...
+func (cylinder) Name() string { return "cylinder" }
+
+// Neighbours ...
+func (cylinder) Neighbours(p Point, width, height int) []Point {
+	return neighbours(p, width, height, true, false)
+}
+
+// neighbours returns the points left, right, below and above p, wrapping
+// around the edges that join up and dropping points that fall off the
+// others.
+func neighbours(p Point, width, height int, wrapX, wrapY bool) []Point {
+	out := make([]Point, 0, 4)
+	for _, d := range []Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		n := Point{X: p.X + d.X, Y: p.Y + d.Y}
+		if wrapX {
+			n.X = (n.X + width) % width
+		}
+		if wrapY {
+			n.Y = (n.Y + height) % height
+		}
+		if n.X < 0 || n.X >= width || n.Y < 0 || n.Y >= height {
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+package gogo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologyNeighbours(t *testing.T) {
+	tests := []struct {
+		topology Topology
+		point    Point
+		expect   []Point
+	}{
+		{topology: Plane, point: Point{0, 0}, expect: []Point{{1, 0}, {0, 1}}},
+		{topology: Plane, point: Point{1, 1}, expect: []Point{{0, 1}, {2, 1}, {1, 0}, {1, 2}}},
+		{topology: Cylinder, point: Point{0, 0}, expect: []Point{{4, 0}, {1, 0}, {0, 1}}},
+		{topology: Cylinder, point: Point{4, 3}, expect: []Point{{3, 3}, {0, 3}, {4, 2}}},
+		{topology: Torus, point: Point{0, 0}, expect: []Point{{4, 0}, {1, 0}, {0, 3}, {0, 1}}},
+		{topology: Torus, point: Point{4, 3}, expect: []Point{{3, 3}, {0, 3}, {4, 2}, {4, 0}}},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v point %v", i, tt.topology.Name(), tt.point), func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.topology.Neighbours(tt.point, 5, 4))
+		})
+	}
+}
+
+func TestCaptureAcrossWrappedEdges(t *testing.T) {
+	tests := []struct {
+		topology Topology
+		inputs   []string
+		expect   string
+	}{
+		// in the corner, A1 only has two liberties on a plane
+		{
+			topology: Plane,
+			inputs:   []string{"B1", "A1", "A2"},
+			expect: `4 X X X X
+3 X X X X
+2 B X X X
+1 X B X X
+  A B C D`,
+		},
+		// but D1 is next to A1 on a cylinder
+		{
+			topology: Cylinder,
+			inputs:   []string{"B1", "A1", "A2"},
+			expect: `4 X X X X
+3 X X X X
+2 B X X X
+1 W B X X
+  A B C D`,
+		},
+		{
+			topology: Cylinder,
+			inputs:   []string{"B1", "A1", "A2", "C3", "D1"},
+			expect: `4 X X X X
+3 X X W X
+2 B X X X
+1 X B X B
+  A B C D`,
+		},
+		// and A4 is too on a torus
+		{
+			topology: Torus,
+			inputs:   []string{"B1", "A1", "A2", "C3", "D1"},
+			expect: `4 X X X X
+3 X X W X
+2 B X X X
+1 W B X B
+  A B C D`,
+		},
+		{
+			topology: Torus,
+			inputs:   []string{"B1", "A1", "A2", "C3", "D1", "C2", "A4"},
+			expect: `4 B X X X
+3 X X W X
+2 B X W X
+1 X B X B
+  A B C D`,
+		},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v inputs %v", i, tt.topology.Name(), strings.Join(tt.inputs, "_")), func(t *testing.T) {
+			board, err := NewBoard(4, WithTopology(tt.topology))
+			require.NoError(t, err)
+			require.NoError(t, play(&board, tt.inputs))
+			assert.Equal(t, tt.expect, board.String())
+		})
+	}
+}
+
+func TestScoringOnWrappedBoards(t *testing.T) {
+	// a black wall down column B and a white wall down column C
+	wall := []string{"B1", "C1", "B2", "C2", "B3", "C3", "B4", "C4"}
+
+	tests := []struct {
+		topology    Topology
+		expectBlack int
+		expectWhite int
+	}{
+		{topology: Plane, expectBlack: 8, expectWhite: 8},
+		// columns A and D join up, so they're touched by both colours
+		{topology: Cylinder, expectBlack: 4, expectWhite: 4},
+		{topology: Torus, expectBlack: 4, expectWhite: 4},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v", i, tt.topology.Name()), func(t *testing.T) {
+			board, err := NewBoard(4, WithTopology(tt.topology))
+			require.NoError(t, err)
+			require.NoError(t, play(&board, wall))
+
+			gotBlack, gotWhite := board.Score()
+			assert.Equal(t, tt.expectBlack, gotBlack, "black score")
+			assert.Equal(t, tt.expectWhite, gotWhite, "white score")
+		})
+	}
+}
+
+func TestTopologyIsSaved(t *testing.T) {
+	inputs := []string{"B1", "A1", "A2", "C3", "D1"}
+
+	board, err := NewBoard(4, WithTopology(Torus))
+	require.NoError(t, err)
+	board.code = "TEST"
+	require.NoError(t, play(&board, inputs))
+
+	// JSON
+	data, err := json.Marshal(&board)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"topology":"torus"`)
+
+	var fromJSON Board
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.Equal(t, Torus, fromJSON.Topology())
+
+	var bad Board
+	assert.Error(t, json.Unmarshal([]byte(strings.Replace(string(data), "torus", "sphere", 1)), &bad))
+
+	// stores
+	store, err := NewFileStore(t.TempDir(), FormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(&board))
+	loaded, err := store.Load("TEST")
+	require.NoError(t, err)
+	assert.Equal(t, Torus, loaded.Topology())
+	assert.Equal(t, board.String(), loaded.String())
+
+	sgfStore, err := NewFileStore(t.TempDir(), FormatSGF)
+	require.NoError(t, err)
+	assert.Error(t, sgfStore.Save(&board))
+
+	// and replays
+	at, err := board.At(3)
+	require.NoError(t, err)
+	assert.Equal(t, Torus, at.Topology())
+
+	rebuilt, err := Rebuild(4, board.Events(), WithTopology(Torus))
+	require.NoError(t, err)
+	assert.Equal(t, board.String(), rebuilt.String())
+}
+
+func TestRenderWrappedBoardToPNG(t *testing.T) {
+	tests := []struct {
+		topology Topology
+		// the colour just inside the left and top edges of the image,
+		// on the first row and column of the grid
+		expectLeft uint8
+		expectTop  uint8
+	}{
+		{topology: Plane, expectLeft: woodIdx, expectTop: woodIdx},
+		{topology: Cylinder, expectLeft: lineIdx, expectTop: woodIdx},
+		{topology: Torus, expectLeft: lineIdx, expectTop: lineIdx},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v", i, tt.topology.Name()), func(t *testing.T) {
+			board, err := NewBoard(9, WithTopology(tt.topology))
+			require.NoError(t, err)
+
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, board.EncodePNG(buf, ImageOptions{Size: 100}))
+			img, err := png.Decode(buf)
+			require.NoError(t, err)
+
+			// the grid starts at (10,10) on a 100px 9x9 board
+			assert.Equal(t, boardPalette[tt.expectLeft], boardPalette.Convert(img.At(1, 10)), "left edge")
+			assert.Equal(t, boardPalette[tt.expectTop], boardPalette.Convert(img.At(10, 1)), "top edge")
+		})
+	}
+}
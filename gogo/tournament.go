@@ -0,0 +1,605 @@
+package gogo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// PairingSystem is how a Tournament decides who plays who each round.
+type PairingSystem string
+
+const (
+	// RoundRobin has everyone play everyone else once.
+	RoundRobin PairingSystem = "round robin"
+	// Swiss pairs players with the same number of wins, without
+	// repeating games.
+	Swiss PairingSystem = "swiss"
+	// McMahon is Swiss, but players start with a score from their rank,
+	// so they're paired with players of similar strength. Everyone at or
+	// above the bar starts with the same score.
+	McMahon PairingSystem = "mcmahon"
+)
+
+// maxPairingSteps is how hard pairing tries to avoid repeating a game
+// before giving up and allowing it.
+const maxPairingSteps int = 100000
+
+// Pairing is one game in a round.
+type Pairing struct {
+	Black Player
+	White Player
+	// Handicap is how many stones black gets. A handicap of one is a
+	// game with no komi, where the weaker player takes black.
+	Handicap int
+	// Game is the board the game is played on.
+	Game *Board
+	// Result is nil until the game has been recorded.
+	Result *GameResult
+}
+
+// Round is the games in one round of a Tournament, and who sat it out if
+// there was an odd number of players.
+type Round struct {
+	Number   int
+	Pairings []Pairing
+	Bye      *Player
+}
+
+// Standing is a player's place in a Tournament. Score is their wins, with
+// half a point for each draw and a point for each bye, added to their
+// starting score in a McMahon tournament. SOS is the sum of their
+// opponents' scores, and SODOS the sum of the scores of the opponents
+// they beat.
+type Standing struct {
+	Place  int
+	Player Player
+	Score  float64
+	SOS    float64
+	SODOS  float64
+}
+
+// Tournament pairs players for a number of rounds, creates their games
+// with NewBoard, collects the results and works out the standings. It's
+// safe for concurrent use.
+type Tournament struct {
+	mu        sync.Mutex
+	system    PairingSystem
+	settings  GameSettings
+	rounds    int
+	bar       Rank
+	handicaps int
+	store     Store
+	players   []Player
+	ranks     map[string]Rank
+	schedule  [][][2]int
+	played    []Round
+}
+
+// TournamentOption configures a Tournament when it's created.
+type TournamentOption func(*Tournament) error
+
+// WithRounds sets how many rounds a Swiss or McMahon tournament has. By
+// default it's enough for one player to be left with all wins. A round
+// robin always has as many rounds as it takes for everyone to play
+// everyone.
+func WithRounds(n int) TournamentOption {
+	return func(t *Tournament) error {
+		if n < 1 {
+			return fmt.Errorf("tournament needs at least one round, not %v", n)
+		}
+		t.rounds = n
+		return nil
+	}
+}
+
+// WithMcMahonBar sets the rank at and above which everyone in a McMahon
+// tournament starts with the same score. By default there's no bar.
+func WithMcMahonBar(bar Rank) TournamentOption {
+	return func(t *Tournament) error {
+		if bar < Kyu(MinKyu) || bar > Dan(MaxDan) {
+			return fmt.Errorf("McMahon bar %v isn't a rank", int(bar))
+		}
+		t.bar = bar
+		return nil
+	}
+}
+
+// WithHandicaps gives the weaker player in each game black and a
+// stone for each rank between the players, up to max. In a McMahon
+// tournament, players above the bar count as being at it. Games with an
+// unranked player are even.
+func WithHandicaps(max int) TournamentOption {
+	return func(t *Tournament) error {
+		if max < 1 || max > MaxHandicap {
+			return fmt.Errorf("maximum handicap of %v isn't between 1 and %v", max, MaxHandicap)
+		}
+		t.handicaps = max
+		return nil
+	}
+}
+
+// WithTournamentStore saves every game the tournament creates to store.
+func WithTournamentStore(store Store) TournamentOption {
+	return func(t *Tournament) error {
+		if store == nil {
+			return fmt.Errorf("tournament store can't be nil")
+		}
+		t.store = store
+		return nil
+	}
+}
+
+// NewTournament creates a tournament with no players, whose games are
+// played with settings.
+func NewTournament(system PairingSystem, settings GameSettings, opts ...TournamentOption) (*Tournament, error) {
+	switch system {
+	case RoundRobin, Swiss, McMahon:
+	default:
+		return nil, fmt.Errorf("unknown pairing system %q", system)
+	}
+	if settings.Size < MinBoardSize || settings.Size > MaxBoardSize {
+		return nil, fmt.Errorf("%w, %v isn't between %v and %v", ErrBoardSize, settings.Size, MinBoardSize, MaxBoardSize)
+	}
+
+	t := &Tournament{system: system, settings: settings, bar: Dan(MaxDan), ranks: map[string]Rank{}}
+	for _, opt := range opts {
+		if err := opt(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// AddPlayer enters a player, before the first round.
+func (t *Tournament) AddPlayer(p Player) error {
+	if p.ID == "" {
+		return fmt.Errorf("player has no ID")
+	}
+	rank, err := ParseRank(p.Rank)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.played) > 0 {
+		return fmt.Errorf("can't add %q, the tournament has started", p.ID)
+	}
+	if _, ok := t.ranks[p.ID]; ok {
+		return fmt.Errorf("%q is already in the tournament", p.ID)
+	}
+	t.players = append(t.players, p)
+	t.ranks[p.ID] = rank
+	t.schedule = nil
+	return nil
+}
+
+// Players returns everyone in the tournament, in the order they entered.
+func (t *Tournament) Players() []Player {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Player(nil), t.players...)
+}
+
+// Rounds returns how many rounds the tournament has.
+func (t *Tournament) Rounds() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.totalRounds()
+}
+
+// totalRounds ...
+func (t *Tournament) totalRounds() int {
+	switch {
+	case t.system == RoundRobin:
+		return len(t.players) - 1 + len(t.players)%2
+	case t.rounds > 0:
+		return t.rounds
+	case len(t.players) < 2:
+		return 1
+	}
+	return int(math.Ceil(math.Log2(float64(len(t.players)))))
+}
+
+// Round returns the round with the given number, counting from 1.
+func (t *Tournament) Round(n int) (Round, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if n < 1 || n > len(t.played) {
+		return Round{}, false
+	}
+	return t.copyRound(t.played[n-1]), true
+}
+
+// copyRound ...
+func (t *Tournament) copyRound(r Round) Round {
+	r.Pairings = append([]Pairing(nil), r.Pairings...)
+	return r
+}
+
+// NextRound pairs the players for the next round and creates their
+// games, once every game in the last round has a result.
+func (t *Tournament) NextRound() (Round, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.players) < 2 {
+		return Round{}, fmt.Errorf("tournament needs at least two players")
+	}
+	if len(t.played) >= t.totalRounds() {
+		return Round{}, fmt.Errorf("all %v rounds have been played", t.totalRounds())
+	}
+	if n := len(t.played); n > 0 {
+		for _, p := range t.played[n-1].Pairings {
+			if p.Result == nil {
+				return Round{}, fmt.Errorf("round %v isn't finished, %v against %v has no result", n, p.Black.ID, p.White.ID)
+			}
+		}
+	}
+
+	var pairs [][2]int
+	bye := -1
+	if t.system == RoundRobin {
+		pairs, bye = t.roundRobin(len(t.played))
+	} else {
+		pairs, bye = t.swiss()
+	}
+
+	round := Round{Number: len(t.played) + 1}
+	if bye >= 0 {
+		p := t.players[bye]
+		round.Bye = &p
+	}
+	for _, pair := range pairs {
+		pairing, err := t.pairing(pair[0], pair[1], len(t.played))
+		if err != nil {
+			return Round{}, err
+		}
+		round.Pairings = append(round.Pairings, pairing)
+	}
+
+	t.played = append(t.played, round)
+	return t.copyRound(round), nil
+}
+
+// roundRobin returns the pairs for the given round, counting from 0, by
+// the circle method: one player stays put while the rest rotate around
+// them. The first player in each pair takes black: the player who stays
+// put alternates, and everyone else swaps with each move round the
+// circle, so everyone has black about half the time.
+func (t *Tournament) roundRobin(round int) ([][2]int, int) {
+	if t.schedule == nil {
+		n := len(t.players)
+		circle := make([]int, 0, n+1)
+		for i := range t.players {
+			circle = append(circle, i)
+		}
+		if n%2 == 1 {
+			circle = append(circle, -1)
+		}
+
+		for r := 0; r < len(circle)-1; r++ {
+			var pairs [][2]int
+			for i := 0; i < len(circle)/2; i++ {
+				a, b := circle[i], circle[len(circle)-1-i]
+				if i == 0 && r%2 == 1 || i > 0 && i%2 == 1 {
+					a, b = b, a
+				}
+				pairs = append(pairs, [2]int{a, b})
+			}
+			t.schedule = append(t.schedule, pairs)
+
+			// keep the first player where they are, and rotate the rest
+			last := circle[len(circle)-1]
+			copy(circle[2:], circle[1:len(circle)-1])
+			circle[1] = last
+		}
+	}
+
+	var pairs [][2]int
+	bye := -1
+	for _, pair := range t.schedule[round] {
+		switch {
+		case pair[0] < 0:
+			bye = pair[1]
+		case pair[1] < 0:
+			bye = pair[0]
+		default:
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, bye
+}
+
+// swiss pairs players in order of their score, each with the next
+// player they haven't played yet, giving a bye to the lowest placed
+// player who hasn't had one. The first player in each pair is the one
+// who's had black least often.
+func (t *Tournament) swiss() ([][2]int, int) {
+	order := t.order()
+
+	bye := -1
+	if len(order)%2 == 1 {
+		byes := map[string]bool{}
+		for _, r := range t.played {
+			if r.Bye != nil {
+				byes[r.Bye.ID] = true
+			}
+		}
+		at := len(order) - 1
+		for i := len(order) - 1; i >= 0; i-- {
+			if !byes[t.players[order[i]].ID] {
+				at = i
+				break
+			}
+		}
+		bye = order[at]
+		order = append(order[:at], order[at+1:]...)
+	}
+
+	met := map[[2]string]bool{}
+	blacks, lastBlack := map[string]int{}, map[string]bool{}
+	for _, r := range t.played {
+		for _, p := range r.Pairings {
+			met[[2]string{p.Black.ID, p.White.ID}] = true
+			met[[2]string{p.White.ID, p.Black.ID}] = true
+			blacks[p.Black.ID]++
+			lastBlack[p.Black.ID], lastBlack[p.White.ID] = true, false
+		}
+	}
+
+	steps := 0
+	avoid := func(a, b int) bool {
+		return met[[2]string{t.players[a].ID, t.players[b].ID}]
+	}
+	pairs, ok := pairFrom(order, avoid, &steps)
+	if !ok {
+		pairs, _ = pairFrom(order, func(a, b int) bool { return false }, &steps)
+	}
+
+	for i, pair := range pairs {
+		a, b := t.players[pair[0]].ID, t.players[pair[1]].ID
+		if blacks[a] > blacks[b] || blacks[a] == blacks[b] && lastBlack[a] {
+			pairs[i] = [2]int{pair[1], pair[0]}
+		}
+	}
+	return pairs, bye
+}
+
+// pairFrom pairs the first player in order with the first player after
+// them that avoid allows, and so on down the list, backtracking when
+// it gets stuck. It gives up after maxPairingSteps.
+func pairFrom(order []int, avoid func(a, b int) bool, steps *int) ([][2]int, bool) {
+	if len(order) == 0 {
+		return nil, true
+	}
+	first := order[0]
+	for i := 1; i < len(order); i++ {
+		if *steps++; *steps > maxPairingSteps {
+			return nil, false
+		}
+		if avoid(first, order[i]) {
+			continue
+		}
+		rest := append(append([]int{}, order[1:i]...), order[i+1:]...)
+		if out, ok := pairFrom(rest, avoid, steps); ok {
+			return append([][2]int{{first, order[i]}}, out...), true
+		}
+	}
+	return nil, false
+}
+
+// order returns the players' indexes from first to last place.
+func (t *Tournament) order() []int {
+	scores, sos, sodos := t.scores()
+	out := make([]int, len(t.players))
+	for i := range out {
+		out[i] = i
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := t.players[out[i]].ID, t.players[out[j]].ID
+		switch {
+		case scores[a] != scores[b]:
+			return scores[a] > scores[b]
+		case sos[a] != sos[b]:
+			return sos[a] > sos[b]
+		case sodos[a] != sodos[b]:
+			return sodos[a] > sodos[b]
+		}
+		return t.ranks[a] > t.ranks[b]
+	})
+	return out
+}
+
+// startScore is the score a player starts the tournament with: their
+// rank, up to the bar, in a McMahon tournament, and zero otherwise.
+// Everyone at the bar starts on zero, and unranked players start with
+// the weakest kyu rank's score.
+func (t *Tournament) startScore(id string) float64 {
+	if t.system != McMahon {
+		return 0
+	}
+	return float64(t.strength(id) - t.bar)
+}
+
+// strength is the rank a player counts as for McMahon scores and
+// handicaps.
+func (t *Tournament) strength(id string) Rank {
+	rank := t.ranks[id]
+	if rank == 0 {
+		rank = Kyu(MinKyu)
+	}
+	if t.system == McMahon && rank > t.bar {
+		rank = t.bar
+	}
+	return rank
+}
+
+// scores returns every player's score, SOS and SODOS from the results so
+// far.
+func (t *Tournament) scores() (map[string]float64, map[string]float64, map[string]float64) {
+	scores := map[string]float64{}
+	for _, p := range t.players {
+		scores[p.ID] = t.startScore(p.ID)
+	}
+
+	// points each player got against each opponent
+	type game struct {
+		opponent string
+		points   float64
+	}
+	games := map[string][]game{}
+	for _, r := range t.played {
+		if r.Bye != nil {
+			scores[r.Bye.ID]++
+		}
+		for _, p := range r.Pairings {
+			if p.Result == nil {
+				continue
+			}
+			black := 0.5
+			switch p.Result.Winner {
+			case blackPlayer:
+				black = 1
+			case whitePlayer:
+				black = 0
+			}
+			scores[p.Black.ID] += black
+			scores[p.White.ID] += 1 - black
+			games[p.Black.ID] = append(games[p.Black.ID], game{opponent: p.White.ID, points: black})
+			games[p.White.ID] = append(games[p.White.ID], game{opponent: p.Black.ID, points: 1 - black})
+		}
+	}
+
+	sos, sodos := map[string]float64{}, map[string]float64{}
+	for id, played := range games {
+		for _, g := range played {
+			sos[id] += scores[g.opponent]
+			sodos[id] += scores[g.opponent] * g.points
+		}
+	}
+	return scores, sos, sodos
+}
+
+// pairing creates the game between two players, the first of whom takes
+// black unless there's a handicap.
+func (t *Tournament) pairing(black, white int, round int) (Pairing, error) {
+	out := Pairing{Black: t.players[black], White: t.players[white]}
+
+	if t.handicaps > 0 && t.ranks[out.Black.ID] != 0 && t.ranks[out.White.ID] != 0 {
+		diff := int(t.strength(out.White.ID) - t.strength(out.Black.ID))
+		if diff < 0 {
+			out.Black, out.White = out.White, out.Black
+			diff = -diff
+		}
+		if diff > t.handicaps {
+			diff = t.handicaps
+		}
+		out.Handicap = diff
+	}
+
+	opts := []Option{WithSeat(blackPlayer, out.Black), WithSeat(whitePlayer, out.White)}
+	switch {
+	case out.Handicap == 1:
+		opts = append(opts, WithKomi(handicapKomi))
+	case out.Handicap > 1:
+		opts = append(opts, WithHandicap(out.Handicap))
+	}
+	b, err := t.settings.newBoard(opts...)
+	if err != nil {
+		return Pairing{}, fmt.Errorf("unable to create round %v game between %v and %v: %w", round+1, out.Black.ID, out.White.ID, err)
+	}
+	// the code is taken from DefaultCodes now, so no other game has it
+	// when results are reported
+	b.Code()
+	if t.store != nil {
+		if err := t.store.Save(&b); err != nil {
+			return Pairing{}, err
+		}
+	}
+	out.Game = &b
+	return out, nil
+}
+
+// Report records the result of the tournament game with the given code,
+// replacing any result it already had.
+func (t *Tournament) Report(code string, result GameResult) error {
+	return t.report(code, nil, result)
+}
+
+// Record records the result of a finished tournament game. b can be the
+// Game of a Pairing, or a copy of it loaded from elsewhere.
+func (t *Tournament) Record(b *Board) error {
+	result, ok := b.GameResult()
+	if !ok {
+		return fmt.Errorf("can't record game %q, it isn't over", b.Code())
+	}
+	return t.report(b.Code(), b, result)
+}
+
+// report records the result of the pairing whose Game is b, or if there
+// isn't one, of the only pairing whose game has the code.
+func (t *Tournament) report(code string, b *Board, result GameResult) error {
+	switch result.Winner {
+	case blackPlayer, whitePlayer:
+	case "":
+		if result.Reason != ReasonDraw {
+			return fmt.Errorf("result of game %q has no winner", code)
+		}
+	default:
+		return fmt.Errorf("result of game %q has an unknown winner %q", code, result.Winner)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var found []*Pairing
+	for r := range t.played {
+		for i := range t.played[r].Pairings {
+			p := &t.played[r].Pairings[i]
+			if b != nil && p.Game == b {
+				p.Result = &result
+				return nil
+			}
+			if p.Game.Code() == code {
+				found = append(found, p)
+			}
+		}
+	}
+	switch len(found) {
+	case 0:
+		return fmt.Errorf("game %q isn't in the tournament: %w", code, ErrGameNotFound)
+	case 1:
+		found[0].Result = &result
+		return nil
+	default:
+		return fmt.Errorf("can't tell which of %v tournament games with code %q to record", len(found), code)
+	}
+}
+
+// Standings returns every player's place, from first to last. Players
+// are ordered by score, then SOS, then SODOS, and share a place when all
+// three are equal.
+func (t *Tournament) Standings() []Standing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	scores, sos, sodos := t.scores()
+	out := make([]Standing, 0, len(t.players))
+	for i, idx := range t.order() {
+		p := t.players[idx]
+		s := Standing{Place: i + 1, Player: p, Score: scores[p.ID], SOS: sos[p.ID], SODOS: sodos[p.ID]}
+		if i > 0 {
+			prev := out[i-1]
+			if prev.Score == s.Score && prev.SOS == s.SOS && prev.SODOS == s.SODOS {
+				s.Place = prev.Place
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,297 @@
+package gogo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	dan3 = Player{ID: "dan3", Rank: "3d"}
+	dan1 = Player{ID: "dan1", Rank: "1d"}
+	kyu2 = Player{ID: "kyu2", Rank: "2k"}
+	kyu5 = Player{ID: "kyu5", Rank: "5k"}
+)
+
+// testTournament creates a tournament of 9x9 games with the players
+// entered.
+func testTournament(t *testing.T, system PairingSystem, players []Player, opts ...TournamentOption) *Tournament {
+	tour, err := NewTournament(system, GameSettings{Size: 9}, opts...)
+	require.NoError(t, err)
+	for _, p := range players {
+		require.NoError(t, tour.AddPlayer(p))
+	}
+	return tour
+}
+
+// finishRound plays out every game in the round, with the players with
+// the given IDs winning and white winning the rest.
+func finishRound(t *testing.T, tour *Tournament, r Round, winners ...string) {
+	won := map[string]bool{}
+	for _, id := range winners {
+		won[id] = true
+	}
+	for _, p := range r.Pairings {
+		loser := blackPlayer
+		if won[p.Black.ID] {
+			loser = whitePlayer
+		}
+		require.NoError(t, p.Game.Resign(loser))
+		require.NoError(t, tour.Record(p.Game))
+	}
+}
+
+// pairingIDs returns the black and white player IDs of each pairing.
+func pairingIDs(r Round) [][2]string {
+	out := [][2]string{}
+	for _, p := range r.Pairings {
+		out = append(out, [2]string{p.Black.ID, p.White.ID})
+	}
+	return out
+}
+
+func TestRoundRobin(t *testing.T) {
+	tests := []struct {
+		players      []Player
+		expectRounds int
+	}{
+		{players: []Player{dan3, dan1}, expectRounds: 1},
+		{players: []Player{dan3, dan1, kyu2, kyu5}, expectRounds: 3},
+		{players: []Player{dan3, dan1, kyu2, kyu5, alice}, expectRounds: 5},
+		{players: []Player{dan3, dan1, kyu2, kyu5, alice, bob}, expectRounds: 5},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v %v players", i, len(tt.players)), func(t *testing.T) {
+			tour := testTournament(t, RoundRobin, tt.players)
+			assert.Equal(t, tt.expectRounds, tour.Rounds())
+
+			met := map[[2]string]int{}
+			blacks, byes := map[string]int{}, map[string]int{}
+			for n := 1; n <= tt.expectRounds; n++ {
+				r, err := tour.NextRound()
+				require.NoError(t, err)
+				assert.Equal(t, n, r.Number)
+
+				seen := map[string]bool{}
+				for _, p := range r.Pairings {
+					for _, id := range []string{p.Black.ID, p.White.ID} {
+						assert.False(t, seen[id], "%v plays twice in round %v", id, n)
+						seen[id] = true
+					}
+					met[[2]string{p.Black.ID, p.White.ID}]++
+					met[[2]string{p.White.ID, p.Black.ID}]++
+					blacks[p.Black.ID]++
+
+					black, ok := p.Game.Seat(blackPlayer)
+					require.True(t, ok)
+					assert.Equal(t, p.Black, black)
+				}
+				if r.Bye != nil {
+					byes[r.Bye.ID]++
+				}
+				finishRound(t, tour, r)
+			}
+
+			for _, a := range tt.players {
+				for _, b := range tt.players {
+					if a.ID != b.ID {
+						assert.Equal(t, 1, met[[2]string{a.ID, b.ID}], "%v and %v", a.ID, b.ID)
+					}
+				}
+				games := len(tt.players) - 1
+				assert.InDelta(t, float64(games)/2, blacks[a.ID], 1, "%v has black %v times", a.ID, blacks[a.ID])
+				assert.Equal(t, len(tt.players)%2, byes[a.ID])
+			}
+
+			_, err := tour.NextRound()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSwiss(t *testing.T) {
+	tour := testTournament(t, Swiss, []Player{kyu2, dan1, kyu5, dan3})
+	assert.Equal(t, 2, tour.Rounds())
+
+	r, err := tour.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, [][2]string{{"dan3", "dan1"}, {"kyu2", "kyu5"}}, pairingIDs(r))
+
+	_, err = tour.NextRound()
+	assert.Error(t, err, "round 1 isn't finished")
+	finishRound(t, tour, r, "dan1", "kyu5")
+
+	// the winners play each other, and each player who had black gets
+	// white
+	r, err = tour.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, [][2]string{{"dan1", "kyu5"}, {"kyu2", "dan3"}}, pairingIDs(r))
+	finishRound(t, tour, r, "dan1")
+
+	_, err = tour.NextRound()
+	assert.Error(t, err, "all rounds have been played")
+	assert.Error(t, tour.AddPlayer(Player{ID: "late"}), "the tournament has started")
+
+	assert.Equal(t, []Standing{
+		{Place: 1, Player: dan1, Score: 2, SOS: 2, SODOS: 2},
+		{Place: 2, Player: dan3, Score: 1, SOS: 2, SODOS: 0},
+		{Place: 2, Player: kyu5, Score: 1, SOS: 2, SODOS: 0},
+		{Place: 4, Player: kyu2, Score: 0, SOS: 2, SODOS: 0},
+	}, tour.Standings())
+}
+
+func TestMcMahon(t *testing.T) {
+	tour := testTournament(t, McMahon, []Player{kyu5, dan1, kyu2, dan3}, WithMcMahonBar(Dan(1)), WithHandicaps(9))
+
+	// everyone at or above the bar starts level
+	standings := tour.Standings()
+	got := map[string]float64{}
+	for _, s := range standings {
+		got[s.Player.ID] = s.Score
+	}
+	assert.Equal(t, map[string]float64{"dan3": 0, "dan1": 0, "kyu2": -2, "kyu5": -5}, got)
+	assert.Equal(t, []int{1, 1, 3, 4}, []int{standings[0].Place, standings[1].Place, standings[2].Place, standings[3].Place})
+
+	r, err := tour.NextRound()
+	require.NoError(t, err)
+	assert.Equal(t, [][2]string{{"dan3", "dan1"}, {"kyu5", "kyu2"}}, pairingIDs(r))
+	assert.Equal(t, []int{0, 3}, []int{r.Pairings[0].Handicap, r.Pairings[1].Handicap})
+	assert.Equal(t, 3, r.Pairings[1].Game.Handicap())
+
+	// an upset lifts kyu5 up to kyu2's starting score
+	finishRound(t, tour, r, "kyu5")
+	got = map[string]float64{}
+	for _, s := range tour.Standings() {
+		got[s.Player.ID] = s.Score
+	}
+	assert.Equal(t, map[string]float64{"dan3": 0, "dan1": 1, "kyu2": -2, "kyu5": -4}, got)
+}
+
+func TestTournamentHandicaps(t *testing.T) {
+	tests := []struct {
+		players      []Player
+		max          int
+		expectBlack  string
+		expectStones int
+		expectKomi   float64
+	}{
+		{players: []Player{dan3, kyu5}, max: 9, expectBlack: "kyu5", expectStones: 7, expectKomi: handicapKomi},
+		{players: []Player{dan3, kyu5}, max: 4, expectBlack: "kyu5", expectStones: 4, expectKomi: handicapKomi},
+		{players: []Player{kyu2, Player{ID: "kyu1", Rank: "1k"}}, max: 9, expectBlack: "kyu2", expectStones: 1, expectKomi: handicapKomi},
+		{players: []Player{dan1, Player{ID: "other", Rank: "1d"}}, max: 9, expectBlack: "dan1", expectKomi: DefaultRuleset.Komi},
+		{players: []Player{dan3, carol}, max: 9, expectBlack: "dan3", expectKomi: DefaultRuleset.Komi},
+	}
+
+	for i, x := range tests {
+		tt := x
+		t.Run(fmt.Sprintf("test %v", i), func(t *testing.T) {
+			tour := testTournament(t, Swiss, tt.players, WithHandicaps(tt.max))
+			r, err := tour.NextRound()
+			require.NoError(t, err)
+			require.Len(t, r.Pairings, 1)
+
+			p := r.Pairings[0]
+			assert.Equal(t, tt.expectBlack, p.Black.ID)
+			assert.Equal(t, tt.expectStones, p.Handicap)
+			assert.Equal(t, tt.expectKomi, p.Game.Komi())
+			if tt.expectStones > 1 {
+				assert.Equal(t, tt.expectStones, p.Game.Handicap())
+			}
+		})
+	}
+}
+
+func TestTournamentSwissBye(t *testing.T) {
+	tour := testTournament(t, Swiss, []Player{dan3, dan1, kyu2}, WithRounds(3))
+
+	byes := map[string]int{}
+	for n := 1; n <= 3; n++ {
+		r, err := tour.NextRound()
+		require.NoError(t, err)
+		require.NotNil(t, r.Bye)
+		byes[r.Bye.ID]++
+		finishRound(t, tour, r)
+	}
+	assert.Equal(t, map[string]int{"dan3": 1, "dan1": 1, "kyu2": 1}, byes)
+}
+
+func TestTournamentStore(t *testing.T) {
+	store := NewMemoryStore()
+	tour := testTournament(t, RoundRobin, []Player{dan3, dan1}, WithTournamentStore(store))
+
+	r, err := tour.NextRound()
+	require.NoError(t, err)
+	loaded, err := store.Load(r.Pairings[0].Game.Code())
+	require.NoError(t, err)
+	black, _ := loaded.Seat(blackPlayer)
+	assert.Equal(t, r.Pairings[0].Black, black)
+
+	// results can be reported from a copy of the game loaded elsewhere
+	require.NoError(t, loaded.Resign(whitePlayer))
+	require.NoError(t, tour.Record(&loaded))
+	got, ok := tour.Round(1)
+	require.True(t, ok)
+	require.NotNil(t, got.Pairings[0].Result)
+	assert.Equal(t, blackPlayer, got.Pairings[0].Result.Winner)
+}
+
+func TestTournamentErrors(t *testing.T) {
+	_, err := NewTournament("knockout", GameSettings{Size: 9})
+	assert.Error(t, err)
+	_, err = NewTournament(Swiss, GameSettings{Size: 2})
+	assert.True(t, errors.Is(err, ErrBoardSize))
+
+	opts := []TournamentOption{WithRounds(0), WithMcMahonBar(0), WithHandicaps(10), WithTournamentStore(nil)}
+	for _, opt := range opts {
+		_, err := NewTournament(Swiss, GameSettings{Size: 9}, opt)
+		assert.Error(t, err)
+	}
+
+	tour := testTournament(t, Swiss, []Player{dan3})
+	assert.Error(t, tour.AddPlayer(dan3), "already entered")
+	assert.Error(t, tour.AddPlayer(Player{ID: "x", Rank: "99k"}))
+	assert.Error(t, tour.AddPlayer(Player{}))
+	_, err = tour.NextRound()
+	assert.Error(t, err, "only one player")
+
+	require.NoError(t, tour.AddPlayer(dan1))
+	r, err := tour.NextRound()
+	require.NoError(t, err)
+	code := r.Pairings[0].Game.Code()
+
+	assert.Error(t, tour.Record(r.Pairings[0].Game), "the game isn't over")
+	assert.True(t, errors.Is(tour.Report("nope", GameResult{Winner: blackPlayer}), ErrGameNotFound))
+	assert.Error(t, tour.Report(code, GameResult{Winner: "red"}))
+	assert.Error(t, tour.Report(code, GameResult{Reason: ReasonResign}))
+	require.NoError(t, tour.Report(code, GameResult{Reason: ReasonDraw}))
+
+	standings := tour.Standings()
+	assert.Equal(t, 0.5, standings[0].Score)
+	assert.Equal(t, standings[0].Place, standings[1].Place)
+}
+
+func TestTournamentSharedCodes(t *testing.T) {
+	tour := testTournament(t, RoundRobin, []Player{dan3, dan1, kyu2, kyu5})
+	r, err := tour.NextRound()
+	require.NoError(t, err)
+	first, second := r.Pairings[0].Game, r.Pairings[1].Game
+	assert.NotEqual(t, first.Code(), second.Code())
+
+	// a game given another's code, say by loading it, can't be mistaken
+	// for it
+	second.code = first.Code()
+	require.NoError(t, second.Resign(blackPlayer))
+	require.NoError(t, tour.Record(second))
+	assert.Error(t, tour.Report(first.Code(), GameResult{Reason: ReasonDraw}))
+
+	got, ok := tour.Round(1)
+	require.True(t, ok)
+	assert.Nil(t, got.Pairings[0].Result)
+	require.NotNil(t, got.Pairings[1].Result)
+	assert.Equal(t, whitePlayer, got.Pairings[1].Result.Winner)
+}